@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"gorm.io/gorm"
+)
+
+// variables des flags des sous-commandes 'abuse'
+var (
+	abuseStatusFlag string
+	abuseCodeFlag   string
+	abuseLimitFlag  int
+	abuseIDFlag     uint
+	abuseReasonFlag string
+)
+
+// AbuseCmd regroupe les commandes de traitement des signalements d'abus.
+var AbuseCmd = &cobra.Command{
+	Use:   "abuse",
+	Short: "Examine les signalements d'abus et désactive ou réactive des liens.",
+}
+
+// AbuseReportsCmd représente la commande 'abuse reports'
+var AbuseReportsCmd = &cobra.Command{
+	Use:   "reports",
+	Short: "Liste les signalements d'abus.",
+	Long: `Cette commande liste les signalements, du plus récent au plus ancien.
+Par défaut, seuls les signalements en attente sont affichés.
+
+Exemples:
+  url-shortener abuse reports
+  url-shortener abuse reports --status=all --code=QeiHcL`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		abuseService, closeDB := openAbuseService()
+		defer closeDB()
+
+		status := abuseStatusFlag
+		switch status {
+		case "all":
+			status = ""
+		case models.ReportOpen, models.ReportDismissed, models.ReportActioned:
+		default:
+			log.Println("ERREUR : --status doit valoir open, dismissed, actioned ou all.")
+			os.Exit(1)
+		}
+
+		reports, err := abuseService.ListReports(cmd.Context(), status, abuseCodeFlag, abuseLimitFlag)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Lien introuvable : %s\n", abuseCodeFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de lister les signalements : %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(reports) == 0 {
+			fmt.Println("Aucun signalement.")
+			return
+		}
+
+		fmt.Printf("%-5s %-12s %-10s %-10s %-20s %s\n", "ID", "CODE", "CATÉGORIE", "STATUT", "REÇU LE", "URL")
+		for _, report := range reports {
+			fmt.Printf("%-5d %-12s %-10s %-10s %-20s %s\n", report.ID, report.Link.ShortCode, report.Category,
+				report.Status, report.CreatedAt.Local().Format(time.DateTime), report.Link.LongURL)
+			if report.Details != "" {
+				fmt.Printf("%-5s Détails : %s\n", "", report.Details)
+			}
+			if report.ReporterEmail != "" {
+				fmt.Printf("%-5s Contact : %s\n", "", report.ReporterEmail)
+			}
+			if report.Resolution != "" {
+				fmt.Printf("%-5s Décision : %s\n", "", report.Resolution)
+			}
+		}
+	},
+}
+
+// AbuseDismissCmd représente la commande 'abuse dismiss'
+var AbuseDismissCmd = &cobra.Command{
+	Use:   "dismiss",
+	Short: "Rejette un signalement en attente (le lien reste actif).",
+	Long: `Exemple:
+  url-shortener abuse dismiss --id=12 --reason="Destination légitime"`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		abuseService, closeDB := openAbuseService()
+		defer closeDB()
+
+		if err := abuseService.DismissReport(cmd.Context(), abuseIDFlag, abuseReasonFlag); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Aucun signalement en attente avec l'ID : %d\n", abuseIDFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de rejeter le signalement : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Signalement %d rejeté.\n", abuseIDFlag)
+	},
+}
+
+// AbuseDisableCmd représente la commande 'abuse disable'
+var AbuseDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Désactive un lien et clôt ses signalements en attente.",
+	Long: `Cette commande désactive un lien : sa redirection affiche une page « lien désactivé »
+et le moniteur cesse de contacter sa destination. Le motif est obligatoire.
+
+Exemple:
+  url-shortener abuse disable --code=QeiHcL --reason="Hameçonnage bancaire"`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		abuseService, closeDB := openAbuseService()
+		defer closeDB()
+
+		link, resolved, err := abuseService.DisableLink(cmd.Context(), abuseCodeFlag, abuseReasonFlag)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Lien introuvable : %s\n", abuseCodeFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de désactiver le lien : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Lien %s (%s) désactivé : %s\n", link.ShortCode, link.LongURL, link.DisabledReason)
+		fmt.Printf("%d signalement(s) clos.\n", resolved)
+	},
+}
+
+// AbuseRestoreCmd représente la commande 'abuse restore'
+var AbuseRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Réactive un lien désactivé.",
+	Long: `Exemple:
+  url-shortener abuse restore --code=QeiHcL`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		abuseService, closeDB := openAbuseService()
+		defer closeDB()
+
+		link, err := abuseService.RestoreLink(cmd.Context(), abuseCodeFlag)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Lien introuvable : %s\n", abuseCodeFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de réactiver le lien : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Lien %s réactivé.\n", link.ShortCode)
+	},
+}
+
+// openAbuseService ouvre la base configurée et retourne le service des signalements
+// ainsi qu'une fonction de fermeture de la connexion.
+func openAbuseService() (*services.AbuseService, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Println("ERREUR : Impossible de charger la configuration globale.")
+		os.Exit(1)
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("ERREUR FATALE : Impossible d'obtenir la base SQL sous-jacente : %v", err)
+	}
+
+	abuseService := services.NewAbuseService(repository.NewAbuseRepository(db), repository.NewLinkRepository(db))
+	return abuseService, func() { sqlDB.Close() }
+}
+
+func init() {
+	AbuseReportsCmd.Flags().StringVar(&abuseStatusFlag, "status", models.ReportOpen, "État des signalements : open, dismissed, actioned ou all")
+	AbuseReportsCmd.Flags().StringVar(&abuseCodeFlag, "code", "", "Restreint la liste aux signalements d'un lien")
+	AbuseReportsCmd.Flags().IntVar(&abuseLimitFlag, "limit", 50, "Nombre maximal de signalements affichés")
+
+	AbuseDismissCmd.Flags().UintVar(&abuseIDFlag, "id", 0, "ID du signalement à rejeter")
+	AbuseDismissCmd.Flags().StringVar(&abuseReasonFlag, "reason", "", "Motif du rejet")
+	AbuseDismissCmd.MarkFlagRequired("id")
+
+	AbuseDisableCmd.Flags().StringVar(&abuseCodeFlag, "code", "", "Code court du lien à désactiver")
+	AbuseDisableCmd.Flags().StringVar(&abuseReasonFlag, "reason", "", "Motif de la désactivation")
+	AbuseDisableCmd.MarkFlagRequired("code")
+	AbuseDisableCmd.MarkFlagRequired("reason")
+
+	AbuseRestoreCmd.Flags().StringVar(&abuseCodeFlag, "code", "", "Code court du lien à réactiver")
+	AbuseRestoreCmd.MarkFlagRequired("code")
+
+	AbuseCmd.AddCommand(AbuseReportsCmd, AbuseDismissCmd, AbuseDisableCmd, AbuseRestoreCmd)
+	cmd2.RootCmd.AddCommand(AbuseCmd)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"gorm.io/gorm"
+)
+
+// variables des flags des sous-commandes 'apikey'
+var (
+	apiKeyNameFlag string
+	apiKeyIDFlag   uint
+)
+
+// APIKeyCmd regroupe les commandes d'administration des clés d'API.
+var APIKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Gère les clés d'accès à l'API REST (création, liste, révocation).",
+}
+
+// APIKeyCreateCmd représente la commande 'apikey create'
+var APIKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Crée une nouvelle clé d'API.",
+	Long: `Cette commande génère une nouvelle clé d'API et l'affiche une seule fois.
+Seule son empreinte est conservée en base : notez-la immédiatement.
+
+Exemple:
+  url-shortener apikey create --name="marketing"`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		plaintext, key, err := apiKeyService.CreateAPIKey(cmd.Context(), apiKeyNameFlag)
+		if err != nil {
+			log.Printf("ERREUR : Impossible de créer la clé d'API : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Clé d'API créée avec succès :")
+		fmt.Printf("ID : %d\n", key.ID)
+		fmt.Printf("Nom : %s\n", key.Name)
+		fmt.Printf("Clé : %s\n", plaintext)
+		fmt.Println("Conservez cette clé : elle ne pourra plus être affichée.")
+	},
+}
+
+// APIKeyListCmd représente la commande 'apikey list'
+var APIKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les clés d'API.",
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		keys, err := apiKeyService.ListAPIKeys(cmd.Context())
+		if err != nil {
+			log.Printf("ERREUR : Impossible de lister les clés d'API : %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(keys) == 0 {
+			fmt.Println("Aucune clé d'API.")
+			return
+		}
+
+		fmt.Printf("%-5s %-20s %-14s %-20s %s\n", "ID", "NOM", "PRÉFIXE", "CRÉÉE LE", "STATUT")
+		for _, key := range keys {
+			status := "active"
+			if key.RevokedAt != nil {
+				status = "révoquée le " + key.RevokedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%-5d %-20s %-14s %-20s %s\n", key.ID, key.Name, key.Prefix+"…", key.CreatedAt.Format(time.DateTime), status)
+		}
+	},
+}
+
+// APIKeyRevokeCmd représente la commande 'apikey revoke'
+var APIKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Révoque une clé d'API.",
+	Long: `Cette commande révoque une clé d'API : elle est immédiatement refusée par l'API.
+Les liens qu'elle a créés continuent d'être redirigés.
+
+Exemple:
+  url-shortener apikey revoke --id=3`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		key, err := apiKeyService.RevokeAPIKey(cmd.Context(), apiKeyIDFlag)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Aucune clé d'API trouvée avec l'ID : %d\n", apiKeyIDFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de révoquer la clé d'API : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Clé d'API %d (%s) révoquée.\n", key.ID, key.Name)
+	},
+}
+
+// openAPIKeyService ouvre la base configurée et retourne le service des clés d'API
+// ainsi qu'une fonction de fermeture de la connexion.
+func openAPIKeyService() (*services.APIKeyService, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Println("ERREUR : Impossible de charger la configuration globale.")
+		os.Exit(1)
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("ERREUR FATALE : Impossible d'obtenir la base SQL sous-jacente : %v", err)
+	}
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	return services.NewAPIKeyService(apiKeyRepo), func() { sqlDB.Close() }
+}
+
+func init() {
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyNameFlag, "name", "", "Nom décrivant l'usage de la clé")
+	APIKeyCreateCmd.MarkFlagRequired("name")
+
+	APIKeyRevokeCmd.Flags().UintVar(&apiKeyIDFlag, "id", 0, "ID de la clé à révoquer")
+	APIKeyRevokeCmd.MarkFlagRequired("id")
+
+	APIKeyCmd.AddCommand(APIKeyCreateCmd, APIKeyListCmd, APIKeyRevokeCmd)
+	cmd2.RootCmd.AddCommand(APIKeyCmd)
+}
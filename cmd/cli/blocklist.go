@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+
+	"gorm.io/gorm"
+)
+
+// variables des flags des sous-commandes 'blocklist'
+var (
+	blocklistDomainFlag  string
+	blocklistReasonFlag  string
+	blocklistFileFlag    string
+	blocklistSourceFlag  string
+	blocklistReplaceFlag bool
+	blocklistURLFlag     string
+)
+
+// BlocklistCmd regroupe les commandes d'administration des règles de domaine et de la liste de blocage.
+var BlocklistCmd = &cobra.Command{
+	Use:   "blocklist",
+	Short: "Gère les domaines autorisés/interdits et la liste de blocage des URLs malveillantes.",
+	Long: `Les destinations des liens sont vérifiées à leur création, puis à chaque passe du moniteur :
+un lien dont la destination devient interdite est bloqué et répond 451.`,
+}
+
+// BlocklistAllowCmd représente la commande 'blocklist allow'
+var BlocklistAllowCmd = &cobra.Command{
+	Use:   "allow",
+	Short: "Ajoute un domaine à la liste d'autorisation.",
+	Long: `Cette commande autorise un domaine et ses sous-domaines.
+Dès qu'un domaine est autorisé, seuls les domaines autorisés sont acceptés.
+
+Exemple:
+  url-shortener blocklist allow --domain="example.com"`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		addDomainRule(cmd.Context(), models.DomainRuleAllow)
+	},
+}
+
+// BlocklistDenyCmd représente la commande 'blocklist deny'
+var BlocklistDenyCmd = &cobra.Command{
+	Use:   "deny",
+	Short: "Interdit un domaine et ses sous-domaines.",
+	Long: `Cette commande interdit un domaine et ses sous-domaines comme destination.
+
+Exemple:
+  url-shortener blocklist deny --domain="phishing.example" --reason="Hameçonnage signalé"`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		addDomainRule(cmd.Context(), models.DomainRuleDeny)
+	},
+}
+
+// BlocklistRemoveCmd représente la commande 'blocklist remove'
+var BlocklistRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Supprime la règle d'un domaine.",
+	Run: func(cmd *cobra.Command, args []string) {
+		blocklistService, closeDB := openBlocklistService()
+		defer closeDB()
+
+		if err := blocklistService.RemoveDomainRule(cmd.Context(), blocklistDomainFlag); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Aucune règle trouvée pour le domaine : %s\n", blocklistDomainFlag)
+				os.Exit(1)
+			}
+			log.Printf("ERREUR : Impossible de supprimer la règle : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Règle du domaine %s supprimée.\n", blocklistDomainFlag)
+	},
+}
+
+// BlocklistListCmd représente la commande 'blocklist list'
+var BlocklistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les règles de domaine et les listes de blocage importées.",
+	Run: func(cmd *cobra.Command, args []string) {
+		blocklistService, closeDB := openBlocklistService()
+		defer closeDB()
+
+		rules, err := blocklistService.ListDomainRules(cmd.Context())
+		if err != nil {
+			log.Printf("ERREUR : Impossible de lister les règles de domaine : %v\n", err)
+			os.Exit(1)
+		}
+		sources, err := blocklistService.ListSources(cmd.Context())
+		if err != nil {
+			log.Printf("ERREUR : Impossible de lister les listes de blocage : %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(rules) == 0 {
+			fmt.Println("Aucune règle de domaine.")
+		} else {
+			fmt.Printf("%-6s %-40s %-20s %s\n", "ACTION", "DOMAINE", "CRÉÉE LE", "MOTIF")
+			for _, rule := range rules {
+				fmt.Printf("%-6s %-40s %-20s %s\n", rule.Action, rule.Domain, rule.CreatedAt.Local().Format(time.DateTime), rule.Reason)
+			}
+		}
+
+		fmt.Println()
+		if len(sources) == 0 {
+			fmt.Println("Aucune liste de blocage importée.")
+			return
+		}
+		fmt.Printf("%-40s %s\n", "LISTE DE BLOCAGE", "ENTRÉES")
+		for _, source := range sources {
+			fmt.Printf("%-40s %d\n", source.Source, source.Entries)
+		}
+	},
+}
+
+// BlocklistImportCmd représente la commande 'blocklist import'
+var BlocklistImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Importe un fichier de liste de blocage (hôtes, préfixes d'URL, empreintes SHA-256).",
+	Long: `Cette commande importe une liste de blocage locale. Une entrée par ligne :
+  - nom d'hôte, ou format hosts ("0.0.0.0 evil.example") : bloque l'hôte et ses sous-domaines
+  - préfixe d'URL, avec ou sans schéma ("https://evil.example/phish")
+  - empreinte SHA-256 (hexadécimal) d'un nom d'hôte ou d'une URL
+Les lignes vides et les commentaires ('#', '!') sont ignorés.
+
+Le nom de la source (par défaut le nom du fichier) permet de remplacer une liste lors de sa mise à jour.
+
+Exemple:
+  url-shortener blocklist import --file=phishing.txt --replace`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		blocklistService, closeDB := openBlocklistService()
+		defer closeDB()
+
+		file, err := os.Open(blocklistFileFlag)
+		if err != nil {
+			log.Printf("ERREUR : Impossible d'ouvrir le fichier : %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		source := blocklistSourceFlag
+		if source == "" {
+			source = filepath.Base(blocklistFileFlag)
+		}
+
+		result, err := blocklistService.Import(cmd.Context(), file, source, blocklistReplaceFlag)
+		if err != nil {
+			log.Printf("ERREUR : Import de la liste de blocage échoué : %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Liste de blocage '%s' importée : %d entrée(s) lue(s), %d ajoutée(s), %d ligne(s) ignorée(s).\n",
+			source, result.Parsed, result.Added, result.Invalid)
+		fmt.Println("Les liens existants seront revérifiés lors de la prochaine passe du moniteur.")
+	},
+}
+
+// BlocklistCheckCmd représente la commande 'blocklist check'
+var BlocklistCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Vérifie si une URL est interdite par les règles de domaine ou la liste de blocage.",
+	Run: func(cmd *cobra.Command, args []string) {
+		blocklistService, closeDB := openBlocklistService()
+		defer closeDB()
+
+		err := blocklistService.Check(cmd.Context(), blocklistURLFlag)
+		if errors.Is(err, services.ErrDestinationBlocked) {
+			fmt.Printf("Destination bloquée : %v\n", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Printf("ERREUR : Impossible de vérifier l'URL : %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Destination autorisée.")
+	},
+}
+
+// addDomainRule ajoute une règle 'action' pour le domaine passé en flag.
+func addDomainRule(ctx context.Context, action string) {
+	blocklistService, closeDB := openBlocklistService()
+	defer closeDB()
+
+	rule, err := blocklistService.AddDomainRule(ctx, blocklistDomainFlag, action, blocklistReasonFlag)
+	if err != nil {
+		log.Printf("ERREUR : Impossible d'ajouter la règle : %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Règle ajoutée : %s %s\n", rule.Action, rule.Domain)
+	if action == models.DomainRuleDeny {
+		fmt.Println("Les liens existants vers ce domaine seront bloqués lors de la prochaine passe du moniteur.")
+	}
+}
+
+// openBlocklistService ouvre la base configurée et retourne le service des listes de blocage
+// ainsi qu'une fonction de fermeture de la connexion.
+func openBlocklistService() (*services.BlocklistService, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Println("ERREUR : Impossible de charger la configuration globale.")
+		os.Exit(1)
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("ERREUR FATALE : Impossible d'obtenir la base SQL sous-jacente : %v", err)
+	}
+
+	blocklistRepo := repository.NewBlocklistRepository(db)
+	return services.NewBlocklistService(blocklistRepo), func() { sqlDB.Close() }
+}
+
+func init() {
+	for _, c := range []*cobra.Command{BlocklistAllowCmd, BlocklistDenyCmd, BlocklistRemoveCmd} {
+		c.Flags().StringVar(&blocklistDomainFlag, "domain", "", "Domaine concerné (les sous-domaines sont inclus)")
+		c.MarkFlagRequired("domain")
+	}
+	BlocklistAllowCmd.Flags().StringVar(&blocklistReasonFlag, "reason", "", "Motif de la règle")
+	BlocklistDenyCmd.Flags().StringVar(&blocklistReasonFlag, "reason", "", "Motif de la règle")
+
+	BlocklistImportCmd.Flags().StringVar(&blocklistFileFlag, "file", "", "Chemin du fichier à importer")
+	BlocklistImportCmd.Flags().StringVar(&blocklistSourceFlag, "source", "", "Nom de la liste (par défaut le nom du fichier)")
+	BlocklistImportCmd.Flags().BoolVar(&blocklistReplaceFlag, "replace", false, "Remplace les entrées déjà importées sous ce nom")
+	BlocklistImportCmd.MarkFlagRequired("file")
+
+	BlocklistCheckCmd.Flags().StringVar(&blocklistURLFlag, "url", "", "URL à vérifier")
+	BlocklistCheckCmd.MarkFlagRequired("url")
+
+	BlocklistCmd.AddCommand(BlocklistAllowCmd, BlocklistDenyCmd, BlocklistRemoveCmd, BlocklistListCmd,
+		BlocklistImportCmd, BlocklistCheckCmd)
+	cmd2.RootCmd.AddCommand(BlocklistCmd)
+}
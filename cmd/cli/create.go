@@ -5,19 +5,33 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
-
-	sqlite "github.com/glebarez/sqlite" // DRIVER SQLITE 100% Go (pas de CGO)
-	"gorm.io/gorm"
 )
 
 // stocke la valeur du flag --url
 var longURLFlag string
 
+// stocke la valeur du flag --alias (optionnel)
+var aliasFlag string
+
+// stockent les valeurs des flags d'expiration (optionnels)
+var (
+	expiresInFlag time.Duration
+	maxClicksFlag int
+)
+
+// stockent la politique de lien mort du lien (optionnelle)
+var (
+	deadLinkPolicyFlag string
+	fallbackURLFlag    string
+)
+
 // CreateCmd représente la commande 'create'
 var CreateCmd = &cobra.Command{
 	Use:   "create",
@@ -25,7 +39,10 @@ var CreateCmd = &cobra.Command{
 	Long: `Cette commande raccourcit une URL longue fournie et affiche le code court généré.
 
 Exemple:
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://www.example.com/soldes" --alias="summer-sale"
+  url-shortener create --url="https://www.example.com/promo" --expires-in=72h --max-clicks=100
+  url-shortener create --url="https://www.example.com/doc" --dead-link-policy=fallback --fallback-url="https://www.example.com/"`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -48,10 +65,10 @@ Exemple:
 			os.Exit(1)
 		}
 
-		// Connexion SQLite via glebarez/sqlite (sans CGO)
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		// Connexion à la base configurée (SQLite, PostgreSQL ou MySQL)
+		db, err := database.Open(cfg.Database)
 		if err != nil {
-			log.Fatalf("ERREUR : Impossible d'ouvrir la base SQLite : %v", err)
+			log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
 		}
 
 		sqlDB, err := db.DB()
@@ -62,10 +79,26 @@ Exemple:
 
 		// Initialiser repository + service
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		generator, err := cmd2.NewShortCodeGenerator(db)
+		if err != nil {
+			log.Fatalf("ERREUR : Configuration des codes courts invalide : %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, generator, cmd2.NewDestinationPolicy(),
+			services.NewBlocklistService(repository.NewBlocklistRepository(db)))
 
 		// Créer le lien court
-		link, err := linkService.CreateLink(longURLFlag)
+		opts := services.CreateLinkOptions{
+			Alias:          aliasFlag,
+			MaxClicks:      maxClicksFlag,
+			DeadLinkPolicy: deadLinkPolicyFlag,
+			FallbackURL:    fallbackURLFlag,
+		}
+		if expiresInFlag > 0 {
+			expiresAt := time.Now().Add(expiresInFlag)
+			opts.ExpiresAt = &expiresAt
+		}
+
+		link, err := linkService.CreateLink(cmd.Context(), longURLFlag, opts)
 		if err != nil {
 			log.Printf("ERREUR : Impossible de créer le lien court : %v\n", err)
 			os.Exit(1)
@@ -76,6 +109,12 @@ Exemple:
 		fmt.Println("URL courte créée avec succès :")
 		fmt.Printf("Code : %s\n", link.ShortCode)
 		fmt.Printf("URL complète : %s\n", fullShortURL)
+		if link.ExpiresAt != nil {
+			fmt.Printf("Expire le : %s\n", link.ExpiresAt.Local().Format(time.RFC3339))
+		}
+		if link.MaxClicks > 0 {
+			fmt.Printf("Clics maximum : %d\n", link.MaxClicks)
+		}
 	},
 }
 
@@ -84,6 +123,17 @@ func init() {
 	// Définir le flag --url
 	CreateCmd.Flags().StringVar(&longURLFlag, "url", "", "URL longue à raccourcir")
 
+	// Définir le flag --alias
+	CreateCmd.Flags().StringVar(&aliasFlag, "alias", "", "Alias personnalisé à utiliser comme code court (optionnel)")
+
+	// Définir les flags d'expiration
+	CreateCmd.Flags().DurationVar(&expiresInFlag, "expires-in", 0, "Durée de validité du lien (ex: 24h, 90m) (optionnel)")
+	CreateCmd.Flags().IntVar(&maxClicksFlag, "max-clicks", 0, "Nombre maximal de clics autorisés, 0 = illimité (optionnel)")
+
+	// Définir les flags de la politique de lien mort
+	CreateCmd.Flags().StringVar(&deadLinkPolicyFlag, "dead-link-policy", "", "Politique si la destination est hors service : keep, interstitial, fallback ou unavailable (optionnel)")
+	CreateCmd.Flags().StringVar(&fallbackURLFlag, "fallback-url", "", "URL de repli de la politique fallback (optionnel)")
+
 	// Rendre le flag obligatoire
 	CreateCmd.MarkFlagRequired("url")
 
@@ -3,49 +3,214 @@ package cli
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
-	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/spf13/cobra"
-
-	sqlite "github.com/glebarez/sqlite" // DRIVER 100% GO (PAS modernc)
-	"gorm.io/gorm"
 )
 
+// migrateDirFlag est le répertoire des migrations SQL embarquées, utilisé par 'migrate create'.
+var migrateDirFlag string
+
 // MigrateCmd représente la commande 'migrate'
 var MigrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Exécute les migrations pour créer ou mettre à jour les tables.",
+	Long: `Les migrations sont numérotées et embarquées dans l'exécutable ; celles déjà appliquées
+sont enregistrées dans la table 'schema_migrations'. Sans sous-commande, 'migrate' équivaut à 'migrate up'.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		migrateUp()
+	},
+}
 
-		cfg := cmd2.Cfg
-		if cfg == nil {
-			log.Fatalf("ERREUR : Impossible de charger la configuration globale.")
+// MigrateUpCmd représente la commande 'migrate up'
+var MigrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applique toutes les migrations en attente.",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrateUp()
+	},
+}
+
+// MigrateDownCmd représente la commande 'migrate down'
+var MigrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "Annule les N dernières migrations appliquées.",
+	Long: `Exemple:
+  url-shortener migrate down 1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			log.Fatalf("ERREUR : N doit être un entier positif, reçu : %s", args[0])
 		}
 
-		// Ouverture DB SANS CGO - SANS modernc
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		migrator, closeDB := openMigrator()
+		defer closeDB()
+
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Migration %04d_%s annulée.\n", migration.Version, migration.Name)
+		}
 		if err != nil {
-			log.Fatalf("ERREUR : Impossible d'ouvrir la base : %v", err)
+			log.Printf("ERREUR : %v\n", err)
+			os.Exit(1)
 		}
+		if len(reverted) == 0 {
+			fmt.Println("Aucune migration à annuler.")
+		}
+	},
+}
+
+// MigrateStatusCmd représente la commande 'migrate status'
+var MigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Affiche les migrations appliquées et en attente.",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator, closeDB := openMigrator()
+		defer closeDB()
 
-		sqlDB, err := db.DB()
+		statuses, err := migrator.Status()
 		if err != nil {
-			log.Fatalf("ERREUR : Impossible d'obtenir la connexion SQL : %v", err)
+			log.Fatalf("ERREUR : Impossible de lire l'état des migrations : %v", err)
+		}
+
+		pending := 0
+		fmt.Printf("%-8s %-40s %-10s %s\n", "VERSION", "NOM", "ÉTAT", "APPLIQUÉE LE")
+		for _, status := range statuses {
+			state, appliedAt := "appliquée", ""
+			switch {
+			case status.Unknown:
+				state = "inconnue"
+			case status.AppliedAt == nil:
+				state = "en attente"
+				pending++
+			}
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Local().Format(time.DateTime)
+			}
+			fmt.Printf("%-8s %-40s %-10s %s\n", fmt.Sprintf("%04d", status.Version), status.Name, state, appliedAt)
+		}
+		fmt.Printf("\n%d migration(s) en attente.\n", pending)
+	},
+}
+
+// MigrateCreateCmd représente la commande 'migrate create'
+var MigrateCreateCmd = &cobra.Command{
+	Use:   "create NOM",
+	Short: "Crée les fichiers SQL (up et down) d'une nouvelle migration.",
+	Long: `Cette commande crée NNNN_nom.up.sql et NNNN_nom.down.sql dans le répertoire des migrations embarquées,
+avec le numéro suivant la dernière migration connue. Les instructions se terminent par un ';' en fin de ligne.
+Un fichier NNNN_nom.<sqlite|postgres|mysql>.up.sql remplace la version générique pour ce dialecte.
+L'exécutable doit être recompilé pour embarquer la nouvelle migration.
+
+Exemple:
+  url-shortener migrate create add_link_title`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(args[0]), "_"), "_")
+		if name == "" {
+			log.Fatalf("ERREUR : Nom de migration invalide : %q", args[0])
 		}
-		defer sqlDB.Close()
 
-		// Migrations GORM
-		if err := db.AutoMigrate(&models.Link{}, &models.Click{}); err != nil {
-			log.Fatalf("ERREUR : Migrations échouées : %v", err)
+		version, err := nextMigrationVersion(migrateDirFlag)
+		if err != nil {
+			log.Fatalf("ERREUR : %v", err)
 		}
 
-		fmt.Println("Migrations exécutées avec succès.")
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(migrateDirFlag, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+			content := fmt.Sprintf("-- Migration %04d_%s (%s)\n", version, name, direction)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				log.Fatalf("ERREUR : Impossible de créer %s : %v", path, err)
+			}
+			fmt.Printf("Créé : %s\n", path)
+		}
 	},
 }
 
-func init() {
-	cmd2.RootCmd.AddCommand(MigrateCmd)
+// migrateUp applique les migrations en attente et affiche celles qui ont été appliquées.
+func migrateUp() {
+	migrator, closeDB := openMigrator()
+	defer closeDB()
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		fmt.Printf("Migration %04d_%s appliquée.\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		log.Printf("ERREUR : Migrations échouées : %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("Base de données à jour, aucune migration en attente.")
+		return
+	}
+	fmt.Println("Migrations exécutées avec succès.")
 }
 
+// nextMigrationVersion retourne le numéro suivant la plus haute migration embarquée ou présente dans 'dir'.
+func nextMigrationVersion(dir string) (int, error) {
+	known, err := migrations.Load("")
+	if err != nil {
+		return 0, err
+	}
+	highest := 0
+	for _, migration := range known {
+		highest = max(highest, migration.Version)
+	}
 
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if version, err := strconv.Atoi(prefix); found && err == nil {
+			highest = max(highest, version)
+		}
+	}
+	return highest + 1, nil
+}
+
+// openMigrator ouvre la base configurée et retourne le gestionnaire de migrations
+// ainsi qu'une fonction de fermeture de la connexion.
+func openMigrator() (*migrations.Migrator, func()) {
+	cfg := cmd2.Cfg
+	if cfg == nil {
+		log.Fatalf("ERREUR : Impossible de charger la configuration globale.")
+	}
+
+	// Connexion à la base configurée (SQLite, PostgreSQL ou MySQL)
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("ERREUR : Impossible d'ouvrir la base : %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("ERREUR : Impossible d'obtenir la connexion SQL : %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		sqlDB.Close()
+		log.Fatalf("ERREUR : Impossible de charger les migrations : %v", err)
+	}
+	return migrator, func() { sqlDB.Close() }
+}
+
+func init() {
+	MigrateCreateCmd.Flags().StringVar(&migrateDirFlag, "dir", filepath.Join("internal", "migrations", "sql"), "Répertoire des migrations SQL embarquées")
+
+	MigrateCmd.AddCommand(MigrateUpCmd, MigrateDownCmd, MigrateStatusCmd, MigrateCreateCmd)
+	cmd2.RootCmd.AddCommand(MigrateCmd)
+}
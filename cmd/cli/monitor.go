@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// MonitorCmd regroupe les commandes liées au moniteur d'URLs.
+var MonitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Consulte l'état des destinations surveillées par le moniteur.",
+}
+
+// MonitorStatusCmd représente la commande 'monitor status'
+var MonitorStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Liste les destinations actuellement inaccessibles.",
+	Long: `Cette commande affiche les liens dont la destination était inaccessible
+lors du dernier contrôle du moniteur, du plus ancien incident au plus récent.
+
+Exemple:
+  url-shortener monitor status`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// Charger la configuration
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Println("ERREUR : Impossible de charger la configuration globale.")
+			os.Exit(1)
+		}
+
+		// Connexion à la base configurée (SQLite, PostgreSQL ou MySQL)
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("ERREUR FATALE : Impossible d'obtenir la base SQL sous-jacente : %v", err)
+		}
+		defer sqlDB.Close()
+
+		healthService := services.NewHealthService(repository.NewHealthRepository(db), services.DeadLinkConfig{})
+
+		broken, err := healthService.ListBrokenLinks(cmd.Context())
+		if err != nil {
+			log.Printf("ERREUR : Impossible de récupérer l'état des destinations : %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(broken) == 0 {
+			fmt.Println("Toutes les destinations surveillées sont accessibles.")
+			return
+		}
+
+		fmt.Printf("%d destination(s) inaccessible(s) :\n\n", len(broken))
+		fmt.Printf("%-12s %-20s %-8s %-7s %s\n", "CODE", "DEPUIS", "ÉCHECS", "STATUT", "URL / ERREUR")
+		for _, h := range broken {
+			status := "-"
+			if h.StatusCode != 0 {
+				status = fmt.Sprintf("%d", h.StatusCode)
+			}
+			fmt.Printf("%-12s %-20s %-8d %-7s %s\n",
+				h.Link.ShortCode, h.LastChangedAt.Local().Format(time.DateTime), h.ConsecutiveFailures, status, h.Link.LongURL)
+			if h.Error != "" {
+				fmt.Printf("%-50s %s\n", "", h.Error)
+			}
+		}
+	},
+}
+
+func init() {
+	MonitorCmd.AddCommand(MonitorStatusCmd)
+	cmd2.RootCmd.AddCommand(MonitorCmd)
+}
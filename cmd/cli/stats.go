@@ -4,19 +4,29 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
 
-	sqlite "github.com/glebarez/sqlite" // DRIVER SQLITE 100% Go (pas de CGO)
 	"gorm.io/gorm"
 )
 
 // variable shortCodeFlag qui stockera la valeur du flag --code
 var shortCodeFlag string
 
+// variables des flags --since et --interval pour la série temporelle (optionnels)
+var (
+	sinceFlag    string
+	intervalFlag string
+)
+
 // StatsCmd représente la commande 'stats'
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
@@ -24,8 +34,12 @@ var StatsCmd = &cobra.Command{
 	Long: `Cette commande permet de récupérer et d'afficher le nombre total de clics
 pour une URL courte spécifique à partir de son code.
 
+Avec --since, la commande affiche également le nombre de clics par intervalle
+(heure, jour ou semaine) sur la période demandée.
+
 Exemple:
-  url-shortener stats --code="xyz123"`,
+  url-shortener stats --code="xyz123"
+  url-shortener stats --code="xyz123" --since=7d --interval=day`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -42,10 +56,10 @@ Exemple:
 			os.Exit(1)
 		}
 
-		// Connexion SQLite via glebarez/sqlite (sans CGO)
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		// Connexion à la base configurée (SQLite, PostgreSQL ou MySQL)
+		db, err := database.Open(cfg.Database)
 		if err != nil {
-			log.Fatalf("ERREUR : Impossible d'ouvrir la base SQLite : %v", err)
+			log.Fatalf("ERREUR : Impossible d'ouvrir la base de données : %v", err)
 		}
 
 		sqlDB, err := db.DB()
@@ -54,12 +68,20 @@ Exemple:
 		}
 		defer sqlDB.Close()
 
-		// Initialiser repository + service
+		// Initialiser repositories + services
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		clickRepo := repository.NewClickRepository(db)
+		generator, err := cmd2.NewShortCodeGenerator(db)
+		if err != nil {
+			log.Fatalf("ERREUR : Configuration des codes courts invalide : %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, generator, cmd2.NewDestinationPolicy(),
+			services.NewBlocklistService(repository.NewBlocklistRepository(db)))
+		clickService := services.NewClickService(clickRepo)
+		healthService := services.NewHealthService(repository.NewHealthRepository(db), services.DeadLinkConfig{})
 
 		// Récupérer les stats
-		link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag)
+		link, totalClicks, err := linkService.GetLinkStats(cmd.Context(), shortCodeFlag)
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
 				log.Printf("Aucun lien trouvé pour le code : %s\n", shortCodeFlag)
@@ -73,6 +95,70 @@ Exemple:
 		fmt.Printf("Statistiques pour le code court : %s\n", link.ShortCode)
 		fmt.Printf("URL longue : %s\n", link.LongURL)
 		fmt.Printf("Total de clics : %d\n", totalClicks)
+		if link.ExpiresAt != nil {
+			fmt.Printf("Expire le : %s\n", link.ExpiresAt.Local().Format(time.RFC3339))
+		}
+		if link.MaxClicks > 0 {
+			fmt.Printf("Clics maximum : %d\n", link.MaxClicks)
+		}
+		if link.IsExpired(time.Now(), totalClicks) {
+			fmt.Println("Statut : expiré")
+		}
+
+		health, err := healthService.GetLinkStatus(cmd.Context(), link.ID)
+		if err != nil {
+			log.Printf("ERREUR : Impossible de récupérer l'état de la destination : %v\n", err)
+			os.Exit(1)
+		}
+		if health != nil && health.CertExpiresAt != nil {
+			hostname := "valide"
+			if !health.CertHostnameValid {
+				hostname = "INVALIDE"
+			}
+			fmt.Printf("Certificat TLS : expire le %s (%d jour(s)), émis par %s, nom d'hôte %s\n",
+				health.CertExpiresAt.Local().Format(time.DateOnly),
+				int(time.Until(*health.CertExpiresAt).Hours()/24), health.CertIssuer, hostname)
+			if health.CertError != "" {
+				fmt.Printf("  Erreur de vérification : %s\n", health.CertError)
+			}
+		}
+
+		breakdown, err := clickService.GetClickBreakdown(cmd.Context(), link.ID)
+		if err != nil {
+			log.Printf("ERREUR : Impossible de récupérer la provenance des clics : %v\n", err)
+			os.Exit(1)
+		}
+		printCounts("Principaux référents", breakdown.Referrers)
+		printCounts("Appareils", breakdown.Devices)
+		printCounts("Navigateurs", breakdown.Browsers)
+		printCounts("Systèmes", breakdown.OS)
+
+		if sinceFlag == "" {
+			return
+		}
+
+		since, err := parseSince(sinceFlag)
+		if err != nil {
+			log.Printf("ERREUR : Valeur de --since invalide : %v\n", err)
+			os.Exit(1)
+		}
+
+		to := time.Now().UTC()
+		buckets, err := clickService.GetClickTimeSeries(cmd.Context(), link.ID, to.Add(-since), to, intervalFlag)
+		if err != nil {
+			log.Printf("ERREUR : Impossible de calculer la série temporelle : %v\n", err)
+			os.Exit(1)
+		}
+
+		layout := time.DateOnly
+		if intervalFlag == "hour" {
+			layout = "2006-01-02 15:00"
+		}
+
+		fmt.Printf("\nClics par %s depuis %s :\n", intervalFlag, sinceFlag)
+		for _, b := range buckets {
+			fmt.Printf("  %-16s %d\n", b.Start.Format(layout), b.Count)
+		}
 	},
 }
 
@@ -81,9 +167,43 @@ func init() {
 	// Définir le flag --code
 	StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "Code court à analyser")
 
+	// Définir les flags de la série temporelle
+	StatsCmd.Flags().StringVar(&sinceFlag, "since", "", "Période à analyser (ex: 12h, 7d, 4w) (optionnel)")
+	StatsCmd.Flags().StringVar(&intervalFlag, "interval", "day", "Granularité de la série temporelle : hour, day ou week")
+
 	// Rendre le flag obligatoire
 	StatsCmd.MarkFlagRequired("code")
 
 	// Ajouter la commande au root
 	cmd2.RootCmd.AddCommand(StatsCmd)
 }
+
+// printCounts affiche une ventilation des clics sous forme de liste "valeur : nombre".
+func printCounts(title string, counts []models.ClickCount) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s :\n", title)
+	for _, c := range counts {
+		fmt.Printf("  %-24s %d\n", c.Value, c.Count)
+	}
+}
+
+// parseSince interprète une durée au format Go (ex: 90m, 12h) ou exprimée en jours ('d') ou semaines ('w').
+func parseSince(value string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(value, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(value)
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("durée invalide %q", value)
+	}
+	return time.Duration(n) * unit, nil
+}
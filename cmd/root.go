@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"log"
 
 	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/shortcode"
+	"github.com/axellelanca/urlshortener/internal/urlguard"
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
+// shortCodeSequence est le nom de la séquence en base utilisée par les générateurs basés sur un compteur.
+const shortCodeSequence = "short_code"
+
 // Cfg est la configuration globale chargée via Viper.
 var Cfg *config.Config
 
@@ -39,8 +47,31 @@ func initConfig() {
 	var err error
 	Cfg, err = config.LoadConfig()
 
+	// Une configuration illisible ou invalide arrête toutes les commandes : aucune ne peut fonctionner sans.
 	if err != nil {
-		log.Printf("Attention: Problème lors du chargement de la configuration: %v. Utilisation des valeurs par défaut.", err)
+		log.Fatalf("FATAL : Configuration invalide : %v", err)
 	}
 }
 
+// NewShortCodeGenerator construit le générateur de codes courts décrit par la configuration.
+// Les stratégies basées sur un compteur utilisent une séquence stockée dans 'db'.
+func NewShortCodeGenerator(db *gorm.DB) (shortcode.Generator, error) {
+	sequenceRepo := repository.NewSequenceRepository(db)
+	counter := shortcode.CounterFunc(func(ctx context.Context) (uint64, error) {
+		return sequenceRepo.NextValue(ctx, shortCodeSequence)
+	})
+
+	return shortcode.New(shortcode.Options{
+		Strategy:  Cfg.ShortCode.Strategy,
+		Length:    Cfg.ShortCode.Length,
+		Alphabet:  Cfg.ShortCode.Alphabet,
+		MinLength: Cfg.ShortCode.MinLength,
+		Salt:      Cfg.ShortCode.Salt,
+		Syllables: Cfg.ShortCode.Syllables,
+	}, counter)
+}
+
+// NewDestinationPolicy construit la politique de sécurité des URLs de destination décrite par la configuration.
+func NewDestinationPolicy() *urlguard.Policy {
+	return urlguard.New(Cfg.Destinations.AllowPrivateNetworks, Cfg.Destinations.DeniedDomains)
+}
@@ -1,26 +1,34 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
-	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/api"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/notifier"
+	"github.com/axellelanca/urlshortener/internal/readiness"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/tracing"
 	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-
-	sqlite "github.com/glebarez/sqlite" // DRIVER 100% GO
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 )
 
@@ -33,43 +41,242 @@ var RunServerCmd = &cobra.Command{
 		if cfg == nil {
 			log.Fatalf("FATAL : Impossible de charger la configuration.")
 		}
+		if err := logging.Setup(cfg.Logging); err != nil {
+			log.Fatalf("FATAL : Configuration des logs invalide : %v", err)
+		}
+		shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+		if err != nil {
+			logging.Fatal("Configuration des traces invalide", "error", err)
+		}
 
-		// Connexion SQLite SANS modernc
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		// Connexion à la base configurée (SQLite, PostgreSQL ou MySQL)
+		db, err := database.Open(cfg.Database)
 		if err != nil {
-			log.Fatalf("FATAL : Impossible d'ouvrir SQLite : %v", err)
+			logging.Fatal("Impossible d'ouvrir la base de données", "error", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			logging.Fatal("Impossible d'obtenir la connexion SQL", "error", err)
+		}
+		slog.Info("Base de données ouverte", "database", database.Describe(cfg.Database))
+		for _, plugin := range []gorm.Plugin{metrics.GormPlugin{}, tracing.GormPlugin{}} {
+			if err := db.Use(plugin); err != nil {
+				logging.Fatal("Impossible d'instrumenter la base de données", "error", err)
+			}
+		}
+
+		// Le schéma doit être à jour : sans auto_migrate, le serveur refuse de démarrer sur une migration en attente.
+		migrator, err := migrations.New(db)
+		if err != nil {
+			logging.Fatal("Impossible de charger les migrations", "error", err)
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			logging.Fatal("Impossible de vérifier les migrations", "error", err)
+		}
+		if len(pending) > 0 {
+			if !cfg.Database.AutoMigrate {
+				logging.Fatal("Migrations en attente : exécutez 'url-shortener migrate up' ou activez database.auto_migrate.",
+					"pending", len(pending), "first", fmt.Sprintf("%04d_%s", pending[0].Version, pending[0].Name))
+			}
+			applied, err := migrator.Up()
+			if err != nil {
+				logging.Fatal("Migrations échouées", "error", err)
+			}
+			slog.Info("Migrations appliquées au démarrage", "count", len(applied))
 		}
 
 		// Repos
-		linkRepo := repository.NewLinkRepository(db)
+		var linkRepo repository.LinkRepository = repository.NewLinkRepository(db)
+		var linkCache *repository.CachedLinkRepository
+		if cfg.Cache.Enabled {
+			// Cache des liens partagé par la redirection, l'API et le moniteur, qui l'invalident à chaque écriture
+			linkCache = repository.NewCachedLinkRepository(linkRepo, repository.LinkCacheConfig{
+				Size:        cfg.Cache.Size,
+				TTL:         time.Duration(cfg.Cache.TTLSeconds) * time.Second,
+				NegativeTTL: time.Duration(cfg.Cache.NegativeTTLSeconds) * time.Second,
+			})
+			linkRepo = linkCache
+			metrics.RegisterLinkCache(linkCache.Stats)
+			slog.Info("Cache des liens activé", "size", cfg.Cache.Size,
+				"ttl_seconds", cfg.Cache.TTLSeconds, "negative_ttl_seconds", cfg.Cache.NegativeTTLSeconds)
+		}
 		clickRepo := repository.NewClickRepository(db)
+		apiKeyRepo := repository.NewAPIKeyRepository(db)
+		healthRepo := repository.NewHealthRepository(db)
+		subscriptionRepo := repository.NewSubscriptionRepository(db)
+		blocklistRepo := repository.NewBlocklistRepository(db)
+		abuseRepo := repository.NewAbuseRepository(db)
 
-		log.Println("Repositories initialisés.")
+		slog.Debug("Repositories initialisés.")
+
+		// Générateur de codes courts
+		generator, err := cmd2.NewShortCodeGenerator(db)
+		if err != nil {
+			logging.Fatal("Configuration des codes courts invalide", "error", err)
+		}
+
+		// Politique de sécurité des destinations (SSRF), partagée par les services et le moniteur
+		destinations := cmd2.NewDestinationPolicy()
 
 		// Services
-		linkService := services.NewLinkService(linkRepo)
+		blocklistService := services.NewBlocklistService(blocklistRepo)
+		linkService := services.NewLinkService(linkRepo, generator, destinations, blocklistService)
+		clickService := services.NewClickService(clickRepo)
+		apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+		if err := services.ValidateDeadLinkPolicy(cfg.DeadLinks.Policy); err != nil {
+			logging.Fatal("Politique de lien mort invalide", "error", err)
+		}
+		healthService := services.NewHealthService(healthRepo, services.DeadLinkConfig{
+			Policy:           cfg.DeadLinks.Policy,
+			FallbackURL:      cfg.DeadLinks.FallbackURL,
+			FailureThreshold: cfg.DeadLinks.FailureThreshold,
+		})
 
-		log.Println("Services métiers initialisés.")
+		// Notifications du moniteur
+		notifyCfg := cfg.Monitor.Notifications
+		smtpCfg := notifier.SMTPConfig{
+			Host:     notifyCfg.SMTP.Host,
+			Port:     notifyCfg.SMTP.Port,
+			Username: notifyCfg.SMTP.Username,
+			Password: notifyCfg.SMTP.Password,
+			From:     notifyCfg.SMTP.From,
+		}
+		subscriptionService := services.NewSubscriptionService(subscriptionRepo, smtpCfg.Enabled(), destinations)
+		abuseService := services.NewAbuseService(abuseRepo, linkRepo)
+
+		slog.Debug("Services métiers initialisés.")
+
+		// Journal des clics non persistés, rejoué avant de démarrer les workers
+		clickJournal, err := workers.OpenClickJournal(cfg.Analytics.JournalPath, cfg.Analytics.JournalQueueSize)
+		if err != nil {
+			logging.Fatal("Impossible d'ouvrir le journal des clics", "error", err)
+		}
+		replayed, err := workers.ReplayJournal(clickJournal, clickRepo, cfg.Analytics.BatchSize, cfg.Analytics.JournalMaxReplayAttempts)
+		if err != nil {
+			slog.Warn("Rejeu partiel du journal des clics", "replayed", replayed, "error", err)
+		} else if replayed > 0 {
+			slog.Info("Clics rejoués depuis le journal", "replayed", replayed)
+		}
 
 		// Channel + workers
 		clickChan := make(chan models.ClickEvent, cfg.Analytics.BufferSize)
-		workers.StartClickWorkers(cfg.Analytics.WorkerCount, clickChan, clickRepo)
+		metrics.RegisterClickChannel(clickChan)
+		clickWorkers := workers.StartClickWorkers(cfg.Analytics.WorkerCount, clickChan, clickRepo, workers.BatchConfig{
+			Size:          cfg.Analytics.BatchSize,
+			FlushInterval: time.Duration(cfg.Analytics.FlushIntervalMs) * time.Millisecond,
+			MaxRetries:    cfg.Analytics.MaxRetries,
+			RetryBackoff:  time.Duration(cfg.Analytics.RetryBackoffMs) * time.Millisecond,
+		}, clickJournal)
+
+		slog.Debug("Channel clic prêt", "buffer", cfg.Analytics.BufferSize, "workers", cfg.Analytics.WorkerCount)
 
-		log.Printf("Channel clic prêt : buffer=%d workers=%d",
-			cfg.Analytics.BufferSize, cfg.Analytics.WorkerCount)
+		// Contexte des processus de fond (moniteur, sweeper), annulé à l'arrêt du serveur
+		backgroundCtx, stopBackground := context.WithCancel(context.Background())
+		defer stopBackground()
+		var background sync.WaitGroup
+
+		// Service de notifications, alimenté par le moniteur
+		var globalNotifiers []notifier.Notifier
+		for _, webhook := range notifyCfg.Webhooks {
+			globalNotifiers = append(globalNotifiers, &notifier.WebhookNotifier{
+				URL:    webhook.URL,
+				Secret: webhook.Secret,
+				Format: webhook.Format,
+			})
+		}
+		if smtpCfg.Enabled() && len(notifyCfg.SMTP.To) > 0 {
+			globalNotifiers = append(globalNotifiers, &notifier.SMTPNotifier{Config: smtpCfg, To: notifyCfg.SMTP.To})
+		}
+		dispatcher := notifier.NewDispatcher(globalNotifiers, subscriptionRepo, smtpCfg, destinations, notifier.DispatcherConfig{
+			QueueSize:    notifyCfg.QueueSize,
+			Timeout:      time.Duration(notifyCfg.TimeoutSeconds) * time.Second,
+			MaxAttempts:  notifyCfg.MaxAttempts,
+			RetryBackoff: time.Duration(notifyCfg.RetryBackoffSeconds) * time.Second,
+			DedupWindow:  time.Duration(notifyCfg.DedupWindowMinutes) * time.Minute,
+		})
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			dispatcher.Start(backgroundCtx)
+		}()
+
+		// Statistiques du cache des liens
+		if linkCache != nil && cfg.Cache.StatsIntervalMinutes > 0 {
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				linkCache.LogStats(backgroundCtx, time.Duration(cfg.Cache.StatsIntervalMinutes)*time.Minute)
+			}()
+		}
+		if linkCache != nil && cfg.Cache.InvalidationPollSeconds > 0 {
+			// Invalidation des liens modifiés par les autres processus (CLI, autres instances)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				linkCache.WatchChanges(backgroundCtx, time.Duration(cfg.Cache.InvalidationPollSeconds)*time.Second)
+			}()
+		}
 
 		// Moniteur d'URL
 		monitorInterval := time.Duration(cfg.Monitor.IntervalMinutes) * time.Minute
-		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval)
-		go urlMonitor.Start()
+		softNotFoundPatterns, err := monitor.CompilePatterns(cfg.Monitor.SoftNotFoundPatterns)
+		if err != nil {
+			logging.Fatal("Configuration du moniteur invalide", "error", err)
+		}
+		urlMonitor := monitor.NewUrlMonitor(linkRepo, healthRepo, dispatcher, monitor.Config{
+			Interval:             monitorInterval,
+			Retention:            time.Duration(cfg.Monitor.HistoryRetentionDays) * 24 * time.Hour,
+			Concurrency:          cfg.Monitor.Concurrency,
+			RequestTimeout:       time.Duration(cfg.Monitor.RequestTimeoutSecs) * time.Second,
+			PerHostInterval:      time.Duration(cfg.Monitor.PerHostIntervalMs) * time.Millisecond,
+			MaxRedirects:         cfg.Monitor.MaxRedirects,
+			MaxBodyBytes:         int64(cfg.Monitor.MaxBodyKB) << 10,
+			SoftNotFoundPatterns: softNotFoundPatterns,
+			CertExpiryWarning:    time.Duration(cfg.Monitor.CertExpiryWarnDays) * 24 * time.Hour,
+			Destinations:         destinations,
+			Blocklist:            blocklistService,
+		})
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			urlMonitor.Start(backgroundCtx)
+		}()
 
-		log.Printf("Monitor démarré (%v)", monitorInterval)
+		slog.Debug("Monitor démarré", "interval", monitorInterval)
+
+		// Sweeper d'expiration des liens
+		sweepInterval := time.Duration(cfg.Expiration.SweepIntervalMinutes) * time.Minute
+		expirySweeper := workers.NewExpirySweeper(linkService, sweepInterval)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			expirySweeper.Start(backgroundCtx)
+		}()
+
+		// Vérifications de la sonde de disponibilité (/readyz)
+		checker := readiness.NewChecker(time.Duration(cfg.Readiness.TimeoutMs) * time.Millisecond)
+		checker.Add("database", readiness.Database(sqlDB, cfg.Database))
+		checker.Add("migrations", readiness.Migrations(migrator))
+		checker.Add("click_channel", readiness.ClickChannel(clickChan, cfg.Readiness.ClickChannelMaxFill))
+		checker.Add("monitor", readiness.Monitor(urlMonitor, cfg.Readiness.MonitorStaleIntervals))
 
 		// Routes
-		router := gin.Default()
-		api.SetupRoutes(router, linkService, clickChan)
+		// Logger et récupération des paniques de Gin remplacés par leurs équivalents slog, avec identifiant de requête.
+		// Le span de chaque requête est ouvert en premier pour englober tout le traitement ; les sondes ne sont pas tracées.
+		// Le mode release supprime les messages [GIN-debug] écrits hors du logger ; les routes sont journalisées en debug.
+		gin.SetMode(gin.ReleaseMode)
+		router := gin.New()
+		router.Use(
+			otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool { return !api.IsProbeRequest(r) })),
+			api.RequestIDMiddleware, api.RequestLogger, api.Recovery(),
+		)
+		api.SetupRoutes(router, linkService, clickService, apiKeyService, healthService, subscriptionService, abuseService, checker, clickChan, clickJournal)
 
-		log.Println("Routes API configurées.")
+		for _, route := range router.Routes() {
+			slog.Debug("Route enregistrée", "method", route.Method, "path", route.Path, "handler", route.Handler)
+		}
+		slog.Debug("Routes API configurées.", "count", len(router.Routes()))
 
 		// Serveur HTTP
 		serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -78,27 +285,115 @@ var RunServerCmd = &cobra.Command{
 			Handler: router,
 		}
 
-        // Serveur asynchrone
+		// Serveur asynchrone
 		go func() {
-			log.Printf("Serveur lancé sur %s ...", serverAddr)
+			slog.Info("Serveur lancé", "addr", serverAddr)
 			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				log.Fatalf("Erreur serveur : %v", err)
+				logging.Fatal("Erreur serveur", "error", err)
 			}
 		}()
 
-		// Shutdown propre
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-		<-quit
+		// Serveur d'administration (métriques), sur un port distinct
+		var adminSrv *http.Server
+		if cfg.Admin.Enabled {
+			adminMux := http.NewServeMux()
+			adminMux.Handle("/metrics", metrics.Handler())
+			adminSrv = &http.Server{
+				Addr:    fmt.Sprintf(":%d", cfg.Admin.Port),
+				Handler: adminMux,
+			}
+			go func() {
+				slog.Info("Serveur d'administration lancé (/metrics)", "addr", adminSrv.Addr)
+				if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logging.Fatal("Erreur serveur d'administration", "error", err)
+				}
+			}()
+		}
 
-		log.Println("Arrêt demandé. Fermeture...")
+		// Attente du signal d'arrêt
+		signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stopSignals()
+		<-signalCtx.Done()
+		stopSignals() // Un second Ctrl+C interrompt immédiatement le processus
 
-		time.Sleep(5 * time.Second)
+		shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeoutSeconds) * time.Second
+		slog.Info("Arrêt demandé, fermeture...", "timeout", shutdownTimeout)
 
-		log.Println("Serveur arrêté.")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		// 1. Arrêt des processus de fond et du serveur HTTP : plus aucune nouvelle requête n'est acceptée
+		//    et les requêtes en cours se terminent.
+		stopBackground()
+		httpStopped := true
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Arrêt du serveur HTTP incomplet", "error", err)
+			httpStopped = false
+		}
+		if adminSrv != nil {
+			if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+				slog.Warn("Arrêt du serveur d'administration incomplet", "error", err)
+			}
+		}
+
+		// 2. Plus aucun handler ne peut émettre de clic : on ferme le channel pour que les workers
+		//    écrivent les événements restants puis se terminent. Si des requêtes sont encore en cours,
+		//    le channel reste ouvert : un handler qui enverrait un clic dans un channel fermé paniquerait.
+		workersStopped := false
+		if httpStopped {
+			close(clickChan)
+			workersStopped = waitWithDeadline(shutdownCtx, clickWorkers)
+			if !workersStopped {
+				slog.Warn("Délai dépassé, certains clics en mémoire n'ont pas été enregistrés.")
+			}
+		} else {
+			slog.Warn("Requêtes encore en cours : les clics en mémoire ne sont pas enregistrés.")
+		}
+
+		// 3. Attente de la fin du moniteur et du sweeper.
+		backgroundStopped := waitWithDeadline(shutdownCtx, &background)
+		if !backgroundStopped {
+			slog.Warn("Délai dépassé avant l'arrêt du moniteur.")
+		}
+
+		// 4. Fermeture des ressources, seulement si plus aucune goroutine ne les utilise : sinon, elles sont
+		//    libérées par la fin du processus, après l'écriture des clics en file pour le journal.
+		if workersStopped && backgroundStopped {
+			if err := clickJournal.Close(); err != nil {
+				slog.Warn("Fermeture du journal des clics", "error", err)
+			}
+			if err := sqlDB.Close(); err != nil {
+				slog.Warn("Fermeture de la base", "error", err)
+			}
+		} else {
+			clickJournal.Drain()
+			slog.Warn("Journal des clics et base laissés ouverts : des goroutines les utilisent encore.")
+		}
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("Export des derniers spans incomplet", "error", err)
+		}
+
+		slog.Info("Serveur arrêté.")
 	},
 }
 
+// waitWithDeadline attend la fin de 'wg' ou l'expiration de 'ctx'.
+// Retourne false si le délai a expiré avant la fin des goroutines.
+func waitWithDeadline(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func init() {
 	cmd2.RootCmd.AddCommand(RunServerCmd)
 }
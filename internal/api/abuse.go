@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReportLinkRequest signale un lien court malveillant (JSON ou formulaire HTML).
+type ReportLinkRequest struct {
+	Category string `json:"category" form:"category" binding:"required"` // phishing | malware | spam | illegal | other
+	Details  string `json:"details" form:"details"`
+	// Email permet de recontacter l'auteur du signalement (facultatif).
+	Email string `json:"email" form:"email"`
+}
+
+// ReportLinkHandler enregistre un signalement d'abus. L'endpoint est public : la réponse est identique
+// que le signalement soit nouveau ou déjà en attente pour cette adresse IP.
+func ReportLinkHandler(abuseService *services.AbuseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ReportLinkRequest
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+
+		shortCode := c.Param("shortCode")
+		_, err := abuseService.ReportLink(c.Request.Context(), shortCode, req.Category, req.Details, req.Email, c.ClientIP())
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+			case errors.Is(err, services.ErrInvalidCategory), errors.Is(err, services.ErrInvalidReporterEmail):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				slog.ErrorContext(c.Request.Context(), "Error recording abuse report", "short_code", shortCode, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record report"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "Report received, thank you. It will be reviewed by our team."})
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// apiKeyContextKey est la clé sous laquelle la clé d'API authentifiée est stockée dans le contexte Gin.
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuthMiddleware exige une clé d'API valide, transmise dans l'en-tête
+// "Authorization: Bearer <clé>" ou "X-API-Key: <clé>".
+func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := apiKeyService.Authenticate(c.Request.Context(), extractAPIKey(c.Request))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer realm="url-shortener"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing, invalid or revoked API key"})
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error authenticating API key", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// extractAPIKey lit la clé d'API depuis les en-têtes de la requête.
+func extractAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// currentAPIKey retourne la clé d'API authentifiée par APIKeyAuthMiddleware.
+func currentAPIKey(c *gin.Context) *models.APIKey {
+	return c.MustGet(apiKeyContextKey).(*models.APIKey)
+}
+
+// ownedLink récupère le lien ':shortCode' appartenant à la clé courante.
+// En cas d'échec, la réponse d'erreur est écrite et false est retourné.
+func ownedLink(c *gin.Context, linkService *services.LinkService) (*models.Link, bool) {
+	shortCode := c.Param("shortCode")
+
+	link, err := linkService.GetOwnedLink(c.Request.Context(), shortCode, currentAPIKey(c).ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+			return nil, false
+		}
+		slog.ErrorContext(c.Request.Context(), "Error retrieving link", "short_code", shortCode, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return nil, false
+	}
+	return link, true
+}
@@ -2,40 +2,119 @@ package api
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/readiness"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/tracing"
+	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 var ClickEventsChannel chan models.ClickEvent
 
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService, clickChan chan models.ClickEvent) {
+// ClickJournal reçoit les événements de clic lorsque ClickEventsChannel est saturé.
+var ClickJournal *workers.ClickJournal
+
+// Paramètres de pagination de la liste des liens.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// resolveRetryAfter est le délai (en secondes) conseillé au client après un échec temporaire de résolution DNS.
+const resolveRetryAfter = 30
+
+// Nombre de contrôles d'accessibilité retournés par défaut (et au maximum) par l'endpoint de santé d'un lien.
+const (
+	defaultHealthHistory = 20
+	maxHealthHistory     = 500
+)
+
+func SetupRoutes(router *gin.Engine, linkService *services.LinkService, clickService *services.ClickService, apiKeyService *services.APIKeyService, healthService *services.HealthService, subscriptionService *services.SubscriptionService, abuseService *services.AbuseService, checker *readiness.Checker, clickChan chan models.ClickEvent, clickJournal *workers.ClickJournal) {
 	// Utiliser le channel passé en paramètre au lieu d'en créer un nouveau
 	ClickEventsChannel = clickChan
+	ClickJournal = clickJournal
 
-	router.GET("/health", HealthCheckHandler)
+	// Sondes : /livez ne vérifie que le processus, /readyz ses dépendances. /health est conservé pour compatibilité.
+	router.GET("/livez", LivenessHandler)
+	router.GET("/health", LivenessHandler)
+	router.GET("/readyz", ReadinessHandler(checker))
 
-	api := router.Group("/api/v1")
+	// Toute l'API de gestion exige une clé ; la redirection reste publique.
+	api := router.Group("/api/v1", APIKeyAuthMiddleware(apiKeyService))
 	{
 		api.POST("/links", CreateShortLinkHandler(linkService))
-		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		api.GET("/links", ListLinksHandler(linkService))
+		api.GET("/links/:shortCode", GetLinkHandler(linkService))
+		api.PATCH("/links/:shortCode", UpdateLinkHandler(linkService))
+		api.DELETE("/links/:shortCode", DeleteLinkHandler(linkService))
+		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService, clickService, healthService))
+		api.GET("/links/:shortCode/stats/timeseries", GetLinkTimeSeriesHandler(linkService, clickService))
+		api.GET("/links/:shortCode/health", GetLinkHealthHandler(linkService, healthService))
+		api.POST("/links/:shortCode/subscriptions", CreateSubscriptionHandler(linkService, subscriptionService))
+		api.GET("/links/:shortCode/subscriptions", ListSubscriptionsHandler(linkService, subscriptionService))
+		api.DELETE("/links/:shortCode/subscriptions/:id", DeleteSubscriptionHandler(linkService, subscriptionService))
 	}
 
-	router.GET("/:shortCode", RedirectHandler(linkService))
+	// Signalement public d'un lien malveillant
+	router.POST("/report/:shortCode", ReportLinkHandler(abuseService))
+
+	router.GET("/:shortCode", countRedirects, RedirectHandler(linkService, healthService))
+}
+
+// destinationUnresolved répond 503 lorsque la destination n'a pas pu être résolue temporairement :
+// contrairement à une destination refusée (400), la même demande peut aboutir plus tard.
+func destinationUnresolved(c *gin.Context, err error) {
+	c.Header("Retry-After", strconv.Itoa(resolveRetryAfter))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+}
+
+// countRedirects compte les redirections par code de statut, une fois la réponse écrite.
+func countRedirects(c *gin.Context) {
+	c.Next()
+	metrics.Redirects.WithLabelValues(strconv.Itoa(c.Writer.Status())).Inc()
 }
 
-func HealthCheckHandler(c *gin.Context) {
+// LivenessHandler répond tant que le processus sert des requêtes, sans vérifier ses dépendances :
+// un redémarrage ne corrigerait pas une base indisponible.
+func LivenessHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// ReadinessHandler retourne le rapport détaillé des vérifications de disponibilité,
+// avec le code 503 si l'une d'elles échoue.
+func ReadinessHandler(checker *readiness.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Run(c.Request.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
+
 type CreateLinkRequest struct {
 	LongURL string `json:"long_url" binding:"required,url"`
+	Alias   string `json:"alias"`
+	// ExpiresAt est une date RFC 3339 au-delà de laquelle le lien renvoie 410 Gone.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// MaxClicks limite le nombre de redirections (0 = illimité).
+	MaxClicks int `json:"max_clicks" binding:"min=0"`
+	// DeadLinkPolicy s'applique lorsque la destination est hors service : keep, interstitial, fallback
+	// ou unavailable (vide = politique globale).
+	DeadLinkPolicy string `json:"dead_link_policy"`
+	FallbackURL    string `json:"fallback_url" binding:"omitempty,url"`
 }
 
 func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
@@ -46,66 +125,286 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
-		link, err := linkService.CreateLink(req.LongURL)
+		ownerID := currentAPIKey(c).ID
+		link, err := linkService.CreateLink(c.Request.Context(), req.LongURL, services.CreateLinkOptions{
+			Alias:          req.Alias,
+			ExpiresAt:      req.ExpiresAt,
+			MaxClicks:      req.MaxClicks,
+			OwnerID:        &ownerID,
+			DeadLinkPolicy: req.DeadLinkPolicy,
+			FallbackURL:    req.FallbackURL,
+		})
 		if err != nil {
-			log.Printf("Error creating link: %v", err)
+			switch {
+			case errors.Is(err, services.ErrInvalidAlias), errors.Is(err, services.ErrReservedAlias),
+				errors.Is(err, services.ErrInvalidExpiration), errors.Is(err, services.ErrInvalidDeadLinkPolicy),
+				errors.Is(err, services.ErrDestinationNotAllowed), errors.Is(err, services.ErrDestinationBlocked):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			case errors.Is(err, services.ErrAliasTaken):
+				c.JSON(http.StatusConflict, gin.H{"error": "Alias '" + req.Alias + "' is already taken"})
+				return
+			case errors.Is(err, services.ErrDestinationUnresolved):
+				destinationUnresolved(c, err)
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error creating link", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create short link"})
 			return
 		}
 
-		baseURL := viper.GetString("server.base_url")
+		c.JSON(http.StatusCreated, linkResponse(link))
+	}
+}
+
+// linkResponse construit la représentation JSON d'un lien renvoyée par l'API.
+func linkResponse(link *models.Link) gin.H {
+	baseURL := viper.GetString("server.base_url")
+
+	return gin.H{
+		"short_code":       link.ShortCode,
+		"long_url":         link.LongURL,
+		"full_short_url":   baseURL + "/" + link.ShortCode,
+		"created_at":       link.CreatedAt,
+		"expires_at":       link.ExpiresAt,
+		"max_clicks":       link.MaxClicks,
+		"expired_at":       link.ExpiredAt,
+		"dead_link_policy": link.DeadLinkPolicy,
+		"fallback_url":     link.FallbackURL,
+		"blocked_at":       link.BlockedAt,
+		"blocked_reason":   link.BlockedReason,
+		"disabled_at":      link.DisabledAt,
+		"disabled_reason":  link.DisabledReason,
+	}
+}
+
+// ListLinksHandler retourne une liste paginée des liens de la clé d'API courante.
+// Paramètres : page (défaut 1), page_size (défaut 20, max 100), sort (ex: "created_at", "-created_at").
+func ListLinksHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+			return
+		}
+		pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size must be between 1 and " + strconv.Itoa(maxPageSize)})
+			return
+		}
+		sort := c.DefaultQuery("sort", "-created_at")
+
+		links, total, err := linkService.ListLinks(c.Request.Context(), currentAPIKey(c).ID, page, pageSize, sort)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidSort) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort field: " + sort})
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error listing links", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list links"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(links))
+		for i := range links {
+			items = append(items, linkResponse(&links[i]))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"links":     items,
+			"page":      page,
+			"page_size": pageSize,
+			"total":     total,
+		})
+	}
+}
+
+func GetLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
+	}
+}
+
+// UpdateLinkRequest modifie un lien : seuls les champs présents sont mis à jour.
+type UpdateLinkRequest struct {
+	LongURL        *string `json:"long_url" binding:"omitempty,url"`
+	DeadLinkPolicy *string `json:"dead_link_policy"`
+	// FallbackURL vide ("") rétablit l'URL de repli globale.
+	FallbackURL *string `json:"fallback_url" binding:"omitempty,url|len=0"`
+}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"short_code":     link.ShortCode,
-			"long_url":       link.LongURL,
-			"full_short_url": baseURL + "/" + link.ShortCode,
+func UpdateLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		if req.LongURL == nil && req.DeadLinkPolicy == nil && req.FallbackURL == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+			return
+		}
+
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		err := linkService.UpdateLink(c.Request.Context(), link, services.UpdateLinkOptions{
+			LongURL:        req.LongURL,
+			DeadLinkPolicy: req.DeadLinkPolicy,
+			FallbackURL:    req.FallbackURL,
 		})
+		if errors.Is(err, services.ErrInvalidDeadLinkPolicy) || errors.Is(err, services.ErrDestinationNotAllowed) ||
+			errors.Is(err, services.ErrDestinationBlocked) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrDestinationUnresolved) {
+			destinationUnresolved(c, err)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error updating link", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update link"})
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
 	}
 }
 
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+func DeleteLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		if err := linkService.DeleteLink(c.Request.Context(), link); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error deleting link", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete link"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RedirectHandler redirige vers l'URL longue. Lorsque le moniteur juge la destination hors service,
+// la politique de lien mort du lien (ou la politique globale) s'applique.
+func RedirectHandler(linkService *services.LinkService, healthService *services.HealthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		shortCode := c.Param("shortCode")
 
-		link, err := linkService.GetLinkByShortCode(shortCode)
+		link, err := linkService.ResolveLink(c.Request.Context(), shortCode)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
 				return
 			}
-			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			if errors.Is(err, services.ErrLinkDisabled) {
+				renderDisabled(c, link)
+				return
+			}
+			if errors.Is(err, services.ErrLinkBlocked) {
+				c.JSON(http.StatusUnavailableForLegalReasons, gin.H{"error": "Destination has been blocked"})
+				return
+			}
+			if errors.Is(err, services.ErrLinkExpired) {
+				c.JSON(http.StatusGone, gin.H{"error": "Short URL has expired"})
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error retrieving link", "short_code", shortCode, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
 
-		clickEvent := models.ClickEvent{
-			LinkID:    link.ID,
-			Timestamp: time.Now(),
-			UserAgent: c.Request.UserAgent(),
-			IP:        c.ClientIP(),
+		decision, err := healthService.DeadLinkAction(c.Request.Context(), link)
+		if err != nil {
+			// L'état de la destination est indisponible : on redirige comme si elle était en service.
+			slog.WarnContext(c.Request.Context(), "Error retrieving destination health, redirecting anyway", "short_code", shortCode, "error", err)
+			decision.Policy = models.DeadLinkKeep
 		}
 
-		select {
-		case ClickEventsChannel <- clickEvent:
+		switch decision.Policy {
+		case models.DeadLinkUnavailable:
+			c.Header("Retry-After", strconv.Itoa(viper.GetInt("monitor.interval_minutes")*60))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Destination is currently unavailable"})
+		case models.DeadLinkInterstitial:
+			recordClick(c, link)
+			renderInterstitial(c, link, decision.Health)
+		case models.DeadLinkFallback:
+			recordClick(c, link)
+			c.Redirect(http.StatusFound, decision.FallbackURL)
 		default:
-			log.Printf("Warning: ClickEventsChannel is full, dropping click event for %s.", shortCode)
+			recordClick(c, link)
+			c.Redirect(http.StatusFound, link.LongURL)
 		}
+	}
+}
+
+// recordClick transmet le clic aux workers sans bloquer la redirection. Le contexte de trace accompagne
+// l'événement : le span d'écriture du lot par les workers y est relié.
+func recordClick(c *gin.Context, link *models.Link) {
+	ctx, span := tracing.Start(c.Request.Context(), "clicks.enqueue")
+	defer span.End()
 
-		c.Redirect(http.StatusFound, link.LongURL)
+	clickEvent := models.ClickEvent{
+		LinkID:       link.ID,
+		Timestamp:    time.Now().UTC(),
+		UserAgent:    c.Request.UserAgent(),
+		IP:           c.ClientIP(),
+		Referrer:     c.Request.Referer(),
+		RequestID:    logging.RequestID(ctx),
+		TraceContext: tracing.Inject(ctx),
+	}
+
+	select {
+	case ClickEventsChannel <- clickEvent:
+		span.SetAttributes(attribute.String("clicks.outcome", "queued"))
+	default:
+		// Channel saturé : le clic est confié au journal local, écrit en arrière-plan, et sera rejoué au prochain démarrage.
+		if !ClickJournal.Enqueue(clickEvent) {
+			metrics.ClickChannelFull.WithLabelValues(metrics.ClickDropped).Inc()
+			span.SetAttributes(attribute.String("clicks.outcome", metrics.ClickDropped))
+			slog.ErrorContext(ctx, "ClickEventsChannel and journal queue are full, dropping click event", "short_code", link.ShortCode)
+			return
+		}
+		metrics.ClickChannelFull.WithLabelValues(metrics.ClickJournaled).Inc()
+		span.SetAttributes(attribute.String("clicks.outcome", metrics.ClickJournaled))
 	}
 }
 
-func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+func GetLinkStatsHandler(linkService *services.LinkService, clickService *services.ClickService, healthService *services.HealthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		shortCode := c.Param("shortCode")
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
 
-		link, totalClicks, err := linkService.GetLinkStats(shortCode)
+		totalClicks, err := clickService.GetClicksCountByLinkID(c.Request.Context(), link.ID)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
-				return
-			}
-			log.Printf("Error retrieving stats for %s: %v", shortCode, err)
+			slog.ErrorContext(c.Request.Context(), "Error retrieving stats", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
+			return
+		}
+
+		breakdown, err := clickService.GetClickBreakdown(c.Request.Context(), link.ID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error retrieving click breakdown", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
+			return
+		}
+
+		health, err := healthService.GetLinkStatus(c.Request.Context(), link.ID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error retrieving health", "short_code", link.ShortCode, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
 			return
 		}
@@ -114,6 +413,156 @@ func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
 			"short_code":   link.ShortCode,
 			"long_url":     link.LongURL,
 			"total_clicks": totalClicks,
+			"expires_at":   link.ExpiresAt,
+			"max_clicks":   link.MaxClicks,
+			"expired":      link.IsExpired(time.Now(), totalClicks),
+			"breakdown":    breakdown,
+			"certificate":  certificateResponse(health),
+		})
+	}
+}
+
+// GetLinkTimeSeriesHandler retourne le nombre de clics d'un lien regroupés par intervalle.
+// Paramètres : from et to (RFC 3339 ou AAAA-MM-JJ, 7 derniers jours par défaut), interval (hour, day ou week).
+func GetLinkTimeSeriesHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		to, err := parseTimeParam(c.Query("to"), time.Now().UTC())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' parameter: " + err.Error()})
+			return
+		}
+		from, err := parseTimeParam(c.Query("from"), to.AddDate(0, 0, -7))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' parameter: " + err.Error()})
+			return
+		}
+		interval := c.DefaultQuery("interval", "day")
+
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		buckets, err := clickService.GetClickTimeSeries(c.Request.Context(), link.ID, from, to, interval)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidInterval) || errors.Is(err, services.ErrInvalidTimeRange) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error retrieving time series", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
+			return
+		}
+
+		var total int64
+		for _, b := range buckets {
+			total += b.Count
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"interval":   interval,
+			"from":       from,
+			"to":         to,
+			"total":      total,
+			"buckets":    buckets,
 		})
 	}
 }
+
+// parseTimeParam interprète un paramètre de requête au format RFC 3339 ou AAAA-MM-JJ (UTC).
+// Si la valeur est vide, 'def' est retournée.
+func parseTimeParam(value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, time.UTC)
+}
+
+// GetLinkHealthHandler retourne l'état courant de la destination d'un lien et l'historique de ses contrôles.
+// Paramètre : limit (nombre de contrôles, 20 par défaut).
+func GetLinkHealthHandler(linkService *services.LinkService, healthService *services.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHealthHistory)))
+		if err != nil || limit < 1 || limit > maxHealthHistory {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxHealthHistory)})
+			return
+		}
+
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		health, history, err := healthService.GetLinkHealth(c.Request.Context(), link.ID, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error retrieving health", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve link health"})
+			return
+		}
+
+		checks := make([]gin.H, 0, len(history))
+		for _, check := range history {
+			checks = append(checks, gin.H{
+				"checked_at":  check.CheckedAt,
+				"accessible":  check.Accessible,
+				"status_code": check.StatusCode,
+				"latency_ms":  check.LatencyMs,
+				"error":       check.Error,
+				"final_url":   check.FinalURL,
+				"redirects":   len(check.RedirectChain),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+			"status":     healthResponse(health),
+			"history":    checks,
+		})
+	}
+}
+
+// healthResponse construit la représentation JSON de l'état courant d'une destination.
+func healthResponse(health *models.LinkHealth) gin.H {
+	if health == nil {
+		return gin.H{"state": "UNKNOWN"}
+	}
+
+	state := "ACCESSIBLE"
+	if !health.Accessible {
+		state = "INACCESSIBLE"
+	}
+	return gin.H{
+		"state":                state,
+		"status_code":          health.StatusCode,
+		"latency_ms":           health.LatencyMs,
+		"error":                health.Error,
+		"final_url":            health.FinalURL,
+		"redirect_chain":       health.RedirectChain,
+		"domain_changed":       health.DomainChanged,
+		"soft_404":             health.SoftNotFound,
+		"certificate":          certificateResponse(health),
+		"consecutive_failures": health.ConsecutiveFailures,
+		"last_checked_at":      health.LastCheckedAt,
+		"last_changed_at":      health.LastChangedAt,
+	}
+}
+
+// certificateResponse construit la représentation JSON du certificat TLS de la destination
+// (nil si la destination n'a pas été contrôlée ou n'est pas servie en HTTPS).
+func certificateResponse(health *models.LinkHealth) gin.H {
+	if health == nil || health.CertExpiresAt == nil {
+		return nil
+	}
+	return gin.H{
+		"expires_at":     health.CertExpiresAt,
+		"days_left":      int(time.Until(*health.CertExpiresAt).Hours() / 24),
+		"issuer":         health.CertIssuer,
+		"hostname_valid": health.CertHostnameValid,
+		"error":          health.CertError,
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// interstitialPage avertit l'utilisateur que la destination semble hors service avant de l'y envoyer.
+var interstitialPage = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Destination indisponible</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 4rem auto; padding: 0 1rem; color: #222; }
+.url { word-break: break-all; font-family: monospace; background: #f4f4f4; padding: .5rem; }
+a.button { display: inline-block; margin-top: 1rem; padding: .6rem 1.2rem; background: #444; color: #fff; text-decoration: none; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>Cette destination semble indisponible</h1>
+<p>Le lien <strong>{{.ShortCode}}</strong> pointe vers :</p>
+<p class="url">{{.LongURL}}</p>
+<p>Nos vérifications automatiques n'arrivent plus à joindre cette page depuis le {{.Since}}. Elle peut être temporairement hors service ou avoir été supprimée.</p>
+<a class="button" href="{{.LongURL}}" rel="nofollow noopener">Continuer quand même</a>
+</body>
+</html>
+`))
+
+// disabledPage remplace la redirection d'un lien désactivé par un administrateur.
+var disabledPage = template.Must(template.New("disabled").Parse(`<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Lien désactivé</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 4rem auto; padding: 0 1rem; color: #222; }
+</style>
+</head>
+<body>
+<h1>Ce lien a été désactivé</h1>
+<p>Le lien <strong>{{.ShortCode}}</strong> a été désactivé par nos équipes suite à un signalement d'abus et ne redirige plus vers sa destination.</p>
+<p>Si vous êtes arrivé ici en suivant ce lien, ne saisissez aucune information personnelle sur la page d'origine.</p>
+</body>
+</html>
+`))
+
+// renderDisabled affiche la page d'un lien désactivé (410 Gone). Le motif de la désactivation n'est pas exposé.
+func renderDisabled(c *gin.Context, link *models.Link) {
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusGone)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := disabledPage.Execute(c.Writer, gin.H{"ShortCode": link.ShortCode}); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error rendering disabled page", "short_code", link.ShortCode, "error", err)
+	}
+}
+
+// renderInterstitial affiche la page d'avertissement d'un lien dont la destination est hors service.
+// L'URL est passée telle quelle : html/template neutralise dans le lien les schémas dangereux (javascript:...)
+// que les liens créés avant le contrôle des destinations peuvent encore porter.
+func renderInterstitial(c *gin.Context, link *models.Link, health *models.LinkHealth) {
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	err := interstitialPage.Execute(c.Writer, gin.H{
+		"ShortCode": link.ShortCode,
+		"LongURL":   link.LongURL,
+		"Since":     health.LastChangedAt.Local().Format("02/01/2006 à 15:04"),
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error rendering interstitial", "short_code", link.ShortCode, "error", err)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDMiddleware reprend l'en-tête X-Request-ID du client s'il est valide, ou en génère un.
+// L'identifiant est renvoyé dans la réponse et porté par le contexte de la requête, pour être
+// ajouté aux logs des handlers (slog.*Context) et transmis aux workers avec les clics.
+func RequestIDMiddleware(c *gin.Context) {
+	id := c.GetHeader(logging.RequestIDHeader)
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	c.Header(logging.RequestIDHeader, id)
+	// L'identifiant est aussi ajouté au span de la requête, pour retrouver la trace à partir des logs.
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", id))
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+	c.Next()
+}
+
+// probePaths sont les routes des sondes, appelées toutes les quelques secondes par l'orchestrateur.
+var probePaths = map[string]bool{"/livez": true, "/readyz": true, "/health": true}
+
+// IsProbeRequest indique si la requête est une sonde de vivacité ou de disponibilité.
+func IsProbeRequest(r *http.Request) bool {
+	return probePaths[r.URL.Path]
+}
+
+// RequestLogger journalise chaque requête une fois la réponse écrite, à la place du logger de Gin :
+// niveau error pour les réponses 5xx, info sinon. Les sondes réussies ne sont journalisées qu'au niveau debug,
+// une sonde de disponibilité en échec l'est au niveau warn.
+func RequestLogger(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	level := slog.LevelInfo
+	switch {
+	case IsProbeRequest(c.Request) && c.Writer.Status() == http.StatusServiceUnavailable:
+		level = slog.LevelWarn
+	case IsProbeRequest(c.Request):
+		level = slog.LevelDebug
+	case c.Writer.Status() >= http.StatusInternalServerError:
+		level = slog.LevelError
+	}
+	slog.Log(c.Request.Context(), level, "HTTP request",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"latency", time.Since(start),
+		"client_ip", c.ClientIP(),
+		"bytes", c.Writer.Size(),
+	)
+}
+
+// Recovery répond 500 à une requête dont le handler a paniqué, et journalise la panique avec sa pile.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "Panic while handling request",
+			"error", recovered, "stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateSubscriptionRequest abonne un destinataire aux changements d'état de la destination d'un lien.
+type CreateSubscriptionRequest struct {
+	Channel string `json:"channel" binding:"required"` // webhook | slack | email
+	Target  string `json:"target" binding:"required"`  // URL du webhook ou adresse e-mail
+	// Secret signe les webhooks JSON (HMAC-SHA256, en-tête X-Urlshortener-Signature).
+	Secret string `json:"secret" binding:"max=128"`
+}
+
+func CreateSubscriptionHandler(linkService *services.LinkService, subscriptionService *services.SubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		sub, err := subscriptionService.CreateSubscription(c.Request.Context(), link.ID, req.Channel, req.Target, req.Secret)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidChannel), errors.Is(err, services.ErrInvalidTarget),
+				errors.Is(err, services.ErrEmailUnavailable), errors.Is(err, services.ErrDestinationNotAllowed):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrTooManySubscriptions):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrDestinationUnresolved):
+				destinationUnresolved(c, err)
+			default:
+				slog.ErrorContext(c.Request.Context(), "Error creating subscription", "short_code", link.ShortCode, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subscription"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, subscriptionResponse(sub))
+	}
+}
+
+func ListSubscriptionsHandler(linkService *services.LinkService, subscriptionService *services.SubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		subs, err := subscriptionService.ListSubscriptions(c.Request.Context(), link.ID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error listing subscriptions", "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list subscriptions"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(subs))
+		for i := range subs {
+			items = append(items, subscriptionResponse(&subs[i]))
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	}
+}
+
+func DeleteSubscriptionHandler(linkService *services.LinkService, subscriptionService *services.SubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription id"})
+			return
+		}
+
+		link, ok := ownedLink(c, linkService)
+		if !ok {
+			return
+		}
+
+		if err := subscriptionService.DeleteSubscription(c.Request.Context(), uint(id), link.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "Error deleting subscription", "subscription_id", id, "short_code", link.ShortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subscription"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// subscriptionResponse construit la représentation JSON d'un abonnement. Le secret n'est jamais renvoyé.
+func subscriptionResponse(sub *models.LinkSubscription) gin.H {
+	return gin.H{
+		"id":         sub.ID,
+		"channel":    sub.Channel,
+		"target":     sub.Target,
+		"signed":     sub.Secret != "",
+		"created_at": sub.CreatedAt,
+	}
+}
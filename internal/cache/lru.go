@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats sont les compteurs d'utilisation d'un cache depuis sa création.
+type Stats struct {
+	Hits      uint64 // Lectures servies par le cache
+	Misses    uint64 // Lectures absentes ou expirées
+	Evictions uint64 // Entrées évincées faute de place
+	Size      int    // Nombre d'entrées actuellement en cache
+}
+
+// HitRatio retourne la proportion de lectures servies par le cache (0 si aucune lecture).
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// generationStripes est le nombre de compteurs de génération entre lesquels les clés sont réparties.
+const generationStripes = 256
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU est un cache borné, sûr pour un usage concurrent : au-delà de 'capacity' entrées,
+// la moins récemment utilisée est évincée. Chaque entrée a sa propre durée de vie.
+//
+// Chaque invalidation incrémente une génération : une valeur lue à la source avant une invalidation
+// concurrente n'est pas mise en cache par SetIfGeneration, qui compare la génération relevée avant la lecture.
+type LRU[K comparable, V any] struct {
+	capacity int
+	mu       sync.Mutex
+	items    map[K]*list.Element
+	order    *list.List // Entrées de la plus récemment utilisée (devant) à la plus ancienne
+
+	seed        maphash.Seed
+	generations [generationStripes]uint64 // Incrémentées par Delete, pour les clés de chaque groupe
+	purges      uint64                    // Incrémenté par DeleteFunc et Purge, qui concernent toutes les clés
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// NewLRU crée un cache d'au plus 'capacity' entrées (minimum 1).
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element, capacity),
+		order:    list.New(),
+		seed:     maphash.MakeSeed(),
+	}
+}
+
+// Get retourne la valeur associée à 'key' si elle est présente et non expirée.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		if time.Now().Before(e.expiresAt) {
+			c.order.MoveToFront(elem)
+			c.hits.Add(1)
+			return e.value, true
+		}
+		c.removeElement(elem)
+	}
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set ajoute ou remplace la valeur de 'key' pour une durée 'ttl'.
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, ttl)
+}
+
+// Generation retourne la génération courante de 'key', à relever avant de lire sa valeur à la source.
+func (c *LRU[K, V]) Generation(key K) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation(key)
+}
+
+// SetIfGeneration ajoute ou remplace la valeur de 'key' si elle n'a pas été invalidée depuis que
+// Generation a retourné 'generation', et indique si la valeur a été mise en cache.
+func (c *LRU[K, V]) SetIfGeneration(key K, value V, ttl time.Duration, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation(key) != generation {
+		return false
+	}
+	c.set(key, value, ttl)
+	return true
+}
+
+func (c *LRU[K, V]) set(key K, value V, ttl time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete retire 'key' du cache.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[c.stripe(key)]++
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// DeleteFunc retire toutes les entrées pour lesquelles 'match' retourne vrai. Les clés absentes du cache
+// pouvant aussi être concernées, la génération de toutes les clés change.
+func (c *LRU[K, V]) DeleteFunc(match func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purges++
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if e := elem.Value.(*entry[K, V]); match(e.key, e.value) {
+			c.removeElement(elem)
+		}
+		elem = next
+	}
+}
+
+// Purge vide le cache (les compteurs sont conservés).
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purges++
+	c.items = make(map[K]*list.Element, c.capacity)
+	c.order.Init()
+}
+
+// Stats retourne les compteurs d'utilisation du cache.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
+
+func (c *LRU[K, V]) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
+
+// generation combine le compteur du groupe de 'key' et celui des invalidations globales, tous deux croissants.
+func (c *LRU[K, V]) generation(key K) uint64 {
+	return c.generations[c.stripe(key)] + c.purges
+}
+
+func (c *LRU[K, V]) stripe(key K) uint64 {
+	return maphash.Comparable(c.seed, key) % generationStripes
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		ops      func(c *LRU[string, int])
+		present  []string
+		absent   []string
+	}{
+		{
+			name:     "oldest entry is evicted",
+			capacity: 2,
+			ops: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Set("c", 3, time.Minute)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name:     "read entry is kept",
+			capacity: 2,
+			ops: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Get("a")
+				c.Set("c", 3, time.Minute)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name:     "replaced entry is kept",
+			capacity: 2,
+			ops: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Set("a", 10, time.Minute)
+				c.Set("c", 3, time.Minute)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name:     "capacity below one holds one entry",
+			capacity: 0,
+			ops: func(c *LRU[string, int]) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU[string, int](tt.capacity)
+			tt.ops(c)
+			for _, key := range tt.present {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("Get(%q) missed, want a hit", key)
+				}
+			}
+			for _, key := range tt.absent {
+				if v, ok := c.Get(key); ok {
+					t.Errorf("Get(%q) = %d, want a miss", key, v)
+				}
+			}
+			if stats := c.Stats(); stats.Evictions != 1 {
+				t.Errorf("Evictions = %d, want 1", stats.Evictions)
+			}
+		})
+	}
+}
+
+func TestLRUExpiration(t *testing.T) {
+	c := NewLRU[string, int](4)
+	c.Set("short", 1, time.Millisecond)
+	c.Set("long", 2, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("short"); ok {
+		t.Error("expired entry was returned")
+	}
+	if v, ok := c.Get("long"); !ok || v != 2 {
+		t.Errorf("Get(long) = %d, %v; want 2, true", v, ok)
+	}
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("Stats = %+v, want 1 hit, 1 miss and 1 entry", stats)
+	}
+	if ratio := stats.HitRatio(); ratio != 0.5 {
+		t.Errorf("HitRatio = %v, want 0.5", ratio)
+	}
+}
+
+func TestLRUInvalidation(t *testing.T) {
+	c := NewLRU[string, int](4)
+	fill := func() {
+		c.Set("a", 1, time.Minute)
+		c.Set("b", 2, time.Minute)
+		c.Set("c", 3, time.Minute)
+	}
+
+	fill()
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("Delete: entry still cached")
+	}
+
+	fill()
+	c.DeleteFunc(func(key string, value int) bool { return value >= 2 })
+	if _, ok := c.Get("a"); !ok {
+		t.Error("DeleteFunc removed a non-matching entry")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("DeleteFunc kept a matching entry")
+	}
+
+	fill()
+	c.Purge()
+	if size := c.Stats().Size; size != 0 {
+		t.Errorf("Purge left %d entries", size)
+	}
+}
+
+func TestLRUSetIfGeneration(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *LRU[string, int])
+		want       bool
+	}{
+		{"no invalidation", func(c *LRU[string, int]) {}, true},
+		{"same key deleted", func(c *LRU[string, int]) { c.Delete("key") }, false},
+		{"DeleteFunc", func(c *LRU[string, int]) { c.DeleteFunc(func(string, int) bool { return false }) }, false},
+		{"Purge", func(c *LRU[string, int]) { c.Purge() }, false},
+		{"plain Set", func(c *LRU[string, int]) { c.Set("key", 0, time.Minute) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU[string, int](4)
+			generation := c.Generation("key")
+			tt.invalidate(c)
+			if got := c.SetIfGeneration("key", 1, time.Minute, generation); got != tt.want {
+				t.Errorf("SetIfGeneration = %v, want %v", got, tt.want)
+			}
+			if v, ok := c.Get("key"); tt.want && (!ok || v != 1) {
+				t.Errorf("Get = %d, %v; want the value set", v, ok)
+			}
+		})
+	}
+
+	// Une invalidation d'une autre clé n'empêche la mise en cache que si les deux clés partagent un groupe.
+	c := NewLRU[string, int](4)
+	generation := c.Generation("key")
+	for i := 0; i < 4*generationStripes; i++ {
+		if other := fmt.Sprint("other", i); c.stripe(other) != c.stripe("key") {
+			c.Delete(other)
+			break
+		}
+	}
+	if !c.SetIfGeneration("key", 1, time.Minute, generation) {
+		t.Error("deleting a key of another stripe invalidated the generation")
+	}
+}
+
+// TestLRUSetIfGenerationRace reproduit un lecteur qui met en cache une valeur lue à la source pendant
+// qu'un écrivain la modifie puis invalide le cache : le cache ne doit jamais garder une valeur périmée.
+func TestLRUSetIfGenerationRace(t *testing.T) {
+	const key = "key"
+	for round := 0; round < 2000; round++ {
+		c := NewLRU[string, int64](4)
+		var source atomic.Int64
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			generation := c.Generation(key)
+			value := source.Load()
+			runtime.Gosched() // Laisse l'écrivain s'intercaler entre la lecture et la mise en cache
+			c.SetIfGeneration(key, value, time.Minute, generation)
+		}()
+		go func() {
+			defer wg.Done()
+			source.Add(1)
+			c.Delete(key)
+		}()
+		wg.Wait()
+
+		if v, ok := c.Get(key); ok && v != source.Load() {
+			t.Fatalf("round %d: cache holds %d after invalidation, source is %d", round, v, source.Load())
+		}
+	}
+}
@@ -11,32 +11,161 @@ import (
 // Les tags `mapstructure` sont utilisés par Viper pour mapper les clés du fichier de config
 // (ou des variables d'environnement) aux champs de la structure Go.
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Analytics AnalyticsConfig `mapstructure:"analytics"`
-	Monitor   MonitorConfig   `mapstructure:"monitor"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Analytics    AnalyticsConfig    `mapstructure:"analytics"`
+	Monitor      MonitorConfig      `mapstructure:"monitor"`
+	Expiration   ExpirationConfig   `mapstructure:"expiration"`
+	ShortCode    ShortCodeConfig    `mapstructure:"shortcode"`
+	DeadLinks    DeadLinksConfig    `mapstructure:"dead_links"`
+	Destinations DestinationsConfig `mapstructure:"destinations"`
+	Cache        CacheConfig        `mapstructure:"cache"`
+	Admin        AdminConfig        `mapstructure:"admin"`
+	Logging      LoggingConfig      `mapstructure:"logging"`
+	Tracing      TracingConfig      `mapstructure:"tracing"`
+	Readiness    ReadinessConfig    `mapstructure:"readiness"`
 }
 
 // ServerConfig contient la configuration du serveur web
 type ServerConfig struct {
-	Port    int    `mapstructure:"port"`
-	BaseURL string `mapstructure:"base_url"`
+	Port                   int    `mapstructure:"port"`
+	BaseURL                string `mapstructure:"base_url"`
+	ShutdownTimeoutSeconds int    `mapstructure:"shutdown_timeout_seconds"`
 }
 
-// DatabaseConfig contient la configuration de la base de données
+// DatabaseConfig contient la configuration de la base de données et de son pool de connexions
 type DatabaseConfig struct {
-	Name string `mapstructure:"name"`
+	Driver                 string `mapstructure:"driver"` // "sqlite", "postgres" ou "mysql"
+	Name                   string `mapstructure:"name"`   // Fichier SQLite, utilisé si 'dsn' est vide
+	DSN                    string `mapstructure:"dsn"`
+	MaxOpenConns           int    `mapstructure:"max_open_conns"`
+	MaxIdleConns           int    `mapstructure:"max_idle_conns"`
+	ConnMaxLifetimeMinutes int    `mapstructure:"conn_max_lifetime_minutes"`
+	ConnMaxIdleTimeMinutes int    `mapstructure:"conn_max_idle_time_minutes"`
+	AutoMigrate            bool   `mapstructure:"auto_migrate"` // Applique les migrations en attente au démarrage du serveur
 }
 
 // AnalyticsConfig contient la configuration pour les analytics asynchrones
 type AnalyticsConfig struct {
-	BufferSize  int `mapstructure:"buffer_size"`
-	WorkerCount int `mapstructure:"worker_count"`
+	BufferSize               int    `mapstructure:"buffer_size"`
+	WorkerCount              int    `mapstructure:"worker_count"`
+	BatchSize                int    `mapstructure:"batch_size"`
+	FlushIntervalMs          int    `mapstructure:"flush_interval_ms"`
+	MaxRetries               int    `mapstructure:"max_retries"`
+	RetryBackoffMs           int    `mapstructure:"retry_backoff_ms"`
+	JournalPath              string `mapstructure:"journal_path"`
+	JournalQueueSize         int    `mapstructure:"journal_queue_size"`          // Clics en attente d'écriture dans le journal
+	JournalMaxReplayAttempts int    `mapstructure:"journal_max_replay_attempts"` // Rejeux en échec avant la mise en quarantaine d'un clic
 }
 
 // MonitorConfig contient la configuration du moniteur d'URLs
 type MonitorConfig struct {
-	IntervalMinutes int `mapstructure:"interval_minutes"`
+	IntervalMinutes      int                 `mapstructure:"interval_minutes"`
+	HistoryRetentionDays int                 `mapstructure:"history_retention_days"`
+	Concurrency          int                 `mapstructure:"concurrency"`
+	RequestTimeoutSecs   int                 `mapstructure:"request_timeout_seconds"`
+	PerHostIntervalMs    int                 `mapstructure:"per_host_interval_ms"`
+	MaxRedirects         int                 `mapstructure:"max_redirects"`
+	MaxBodyKB            int                 `mapstructure:"max_body_kb"`
+	SoftNotFoundPatterns []string            `mapstructure:"soft_404_patterns"`
+	CertExpiryWarnDays   int                 `mapstructure:"cert_expiry_warning_days"`
+	Notifications        NotificationsConfig `mapstructure:"notifications"`
+}
+
+// NotificationsConfig contient la configuration des notifications de changement d'état du moniteur
+type NotificationsConfig struct {
+	Webhooks            []WebhookConfig `mapstructure:"webhooks"`
+	SMTP                SMTPConfig      `mapstructure:"smtp"`
+	QueueSize           int             `mapstructure:"queue_size"`
+	TimeoutSeconds      int             `mapstructure:"timeout_seconds"`
+	MaxAttempts         int             `mapstructure:"max_attempts"`
+	RetryBackoffSeconds int             `mapstructure:"retry_backoff_seconds"`
+	DedupWindowMinutes  int             `mapstructure:"dedup_window_minutes"`
+}
+
+// WebhookConfig décrit un webhook global, notifié pour tous les liens
+type WebhookConfig struct {
+	URL    string `mapstructure:"url"`
+	Secret string `mapstructure:"secret"`
+	Format string `mapstructure:"format"` // "json" ou "slack"
+}
+
+// SMTPConfig contient la configuration du serveur d'envoi des e-mails
+type SMTPConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"` // Destinataires notifiés pour tous les liens
+}
+
+// ExpirationConfig contient la configuration du sweeper d'expiration des liens
+type ExpirationConfig struct {
+	SweepIntervalMinutes int `mapstructure:"sweep_interval_minutes"`
+}
+
+// ShortCodeConfig contient la configuration de la génération des codes courts
+type ShortCodeConfig struct {
+	Strategy  string `mapstructure:"strategy"`
+	Length    int    `mapstructure:"length"`
+	Alphabet  string `mapstructure:"alphabet"`
+	MinLength int    `mapstructure:"min_length"`
+	Salt      string `mapstructure:"salt"`
+	Syllables int    `mapstructure:"syllables"`
+}
+
+// DeadLinksConfig contient la politique globale appliquée aux liens dont la destination est hors service
+type DeadLinksConfig struct {
+	Policy           string `mapstructure:"policy"`
+	FallbackURL      string `mapstructure:"fallback_url"`
+	FailureThreshold int    `mapstructure:"failure_threshold"`
+}
+
+// DestinationsConfig contient la politique de sécurité des URLs de destination (protection SSRF)
+type DestinationsConfig struct {
+	AllowPrivateNetworks bool     `mapstructure:"allow_private_networks"`
+	DeniedDomains        []string `mapstructure:"denied_domains"`
+}
+
+// CacheConfig contient la configuration du cache en mémoire des liens (chemin de redirection)
+type CacheConfig struct {
+	Enabled                 bool `mapstructure:"enabled"`
+	Size                    int  `mapstructure:"size"`
+	TTLSeconds              int  `mapstructure:"ttl_seconds"`
+	NegativeTTLSeconds      int  `mapstructure:"negative_ttl_seconds"`
+	StatsIntervalMinutes    int  `mapstructure:"stats_interval_minutes"`
+	InvalidationPollSeconds int  `mapstructure:"invalidation_poll_seconds"`
+}
+
+// AdminConfig contient la configuration du serveur d'administration (métriques Prometheus),
+// séparé du serveur public
+type AdminConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
+}
+
+// LoggingConfig contient la configuration des logs du serveur
+type LoggingConfig struct {
+	Level  string `mapstructure:"level"`  // debug, info, warn ou error
+	Format string `mapstructure:"format"` // text ou json
+}
+
+// TracingConfig contient la configuration des traces OpenTelemetry
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter"`     // none, otlp, stdout ou file
+	Endpoint    string  `mapstructure:"endpoint"`     // Collecteur OTLP/HTTP (hôte:port)
+	Insecure    bool    `mapstructure:"insecure"`     // OTLP en HTTP plutôt qu'en HTTPS
+	FilePath    string  `mapstructure:"file_path"`    // Fichier de l'exporteur 'file' (un span JSON par ligne)
+	SampleRatio float64 `mapstructure:"sample_ratio"` // Part des traces conservées, de 0 à 1
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// ReadinessConfig contient les seuils de la sonde de disponibilité (/readyz)
+type ReadinessConfig struct {
+	TimeoutMs             int     `mapstructure:"timeout_ms"`              // Délai maximal des vérifications
+	ClickChannelMaxFill   float64 `mapstructure:"click_channel_max_fill"`  // Remplissage du channel des clics jugé saturé (0 à 1)
+	MonitorStaleIntervals int     `mapstructure:"monitor_stale_intervals"` // Intervalles sans activité du moniteur avant de le juger arrêté
 }
 
 // LoadConfig charge la configuration de l'application en utilisant Viper.
@@ -55,10 +184,68 @@ func LoadConfig() (*Config, error) {
 	// Définir les valeurs par défaut pour toutes les options de configuration.
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.shutdown_timeout_seconds", 15)
+	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.name", "url_shortener.db")
+	viper.SetDefault("database.dsn", "")
+	viper.SetDefault("database.max_open_conns", 20)
+	viper.SetDefault("database.max_idle_conns", 5)
+	viper.SetDefault("database.conn_max_lifetime_minutes", 30)
+	viper.SetDefault("database.conn_max_idle_time_minutes", 5)
+	viper.SetDefault("database.auto_migrate", false)
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5)
+	viper.SetDefault("analytics.batch_size", 100)
+	viper.SetDefault("analytics.flush_interval_ms", 1000)
+	viper.SetDefault("analytics.max_retries", 3)
+	viper.SetDefault("analytics.retry_backoff_ms", 200)
+	viper.SetDefault("analytics.journal_path", "clicks.journal")
+	viper.SetDefault("analytics.journal_queue_size", 10000)
+	viper.SetDefault("analytics.journal_max_replay_attempts", 5)
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("monitor.history_retention_days", 30)
+	viper.SetDefault("monitor.concurrency", 10)
+	viper.SetDefault("monitor.request_timeout_seconds", 5)
+	viper.SetDefault("monitor.per_host_interval_ms", 500)
+	viper.SetDefault("monitor.max_redirects", 10)
+	viper.SetDefault("monitor.max_body_kb", 64)
+	viper.SetDefault("monitor.cert_expiry_warning_days", 14)
+	viper.SetDefault("monitor.notifications.queue_size", 100)
+	viper.SetDefault("monitor.notifications.timeout_seconds", 10)
+	viper.SetDefault("monitor.notifications.max_attempts", 3)
+	viper.SetDefault("monitor.notifications.retry_backoff_seconds", 5)
+	viper.SetDefault("monitor.notifications.dedup_window_minutes", 30)
+	viper.SetDefault("monitor.notifications.smtp.port", 587)
+	viper.SetDefault("expiration.sweep_interval_minutes", 1)
+	viper.SetDefault("shortcode.strategy", "random")
+	viper.SetDefault("shortcode.length", 6)
+	viper.SetDefault("shortcode.alphabet", "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	viper.SetDefault("shortcode.min_length", 4)
+	viper.SetDefault("shortcode.salt", "")
+	viper.SetDefault("shortcode.syllables", 4)
+	viper.SetDefault("dead_links.policy", "keep")
+	viper.SetDefault("dead_links.fallback_url", "")
+	viper.SetDefault("dead_links.failure_threshold", 3)
+	viper.SetDefault("destinations.allow_private_networks", false)
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.size", 10000)
+	viper.SetDefault("cache.ttl_seconds", 60)
+	viper.SetDefault("cache.negative_ttl_seconds", 10)
+	viper.SetDefault("cache.stats_interval_minutes", 60)
+	viper.SetDefault("cache.invalidation_poll_seconds", 2)
+	viper.SetDefault("admin.enabled", true)
+	viper.SetDefault("admin.port", 8081)
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.format", "text")
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.file_path", "traces.jsonl")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.service_name", "urlshortener")
+	viper.SetDefault("readiness.timeout_ms", 2000)
+	viper.SetDefault("readiness.click_channel_max_fill", 0.9)
+	viper.SetDefault("readiness.monitor_stale_intervals", 3)
 
 	// Lire le fichier de configuration.
 	if err := viper.ReadInConfig(); err != nil {
@@ -74,10 +261,51 @@ func LoadConfig() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unable to decode config into struct: %w", err)
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 
 	// Log pour vérifier la config chargée
-	log.Printf("Configuration loaded: Server Port=%d, DB Name=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
-		cfg.Server.Port, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
+	log.Printf("Configuration loaded: Server Port=%d, DB Driver=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
+		cfg.Server.Port, cfg.Database.Driver, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
 
 	return &cfg, nil // Retourne la configuration chargée
 }
+
+// maxShortCodeLength est la taille de la colonne links.short_code.
+const maxShortCodeLength = 32
+
+// validate refuse les valeurs qui feraient échouer le serveur au démarrage (un intervalle nul
+// fait paniquer time.NewTicker) plutôt que de les découvrir en production.
+func (cfg *Config) validate() error {
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"analytics.batch_size", cfg.Analytics.BatchSize},
+		{"analytics.flush_interval_ms", cfg.Analytics.FlushIntervalMs},
+		{"analytics.journal_queue_size", cfg.Analytics.JournalQueueSize},
+		{"analytics.journal_max_replay_attempts", cfg.Analytics.JournalMaxReplayAttempts},
+		{"expiration.sweep_interval_minutes", cfg.Expiration.SweepIntervalMinutes},
+		{"monitor.interval_minutes", cfg.Monitor.IntervalMinutes},
+	} {
+		if setting.value <= 0 {
+			return fmt.Errorf("invalid %s %d (expected a positive value)", setting.key, setting.value)
+		}
+	}
+
+	// Un code plus long que la colonne links.short_code serait tronqué ou refusé par la base.
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"shortcode.length", cfg.ShortCode.Length},
+		{"shortcode.min_length", cfg.ShortCode.MinLength},
+		{"shortcode.syllables", 2 * cfg.ShortCode.Syllables},
+	} {
+		if setting.value > maxShortCodeLength {
+			return fmt.Errorf("invalid %s: codes would exceed %d characters", setting.key, maxShortCodeLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/logging"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	sqlite "github.com/glebarez/sqlite" // DRIVER SQLITE 100% Go (pas de CGO)
+)
+
+// Pilotes de base de données supportés.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Open ouvre la base décrite par la configuration et applique les réglages du pool de connexions.
+// Les erreurs des pilotes sont traduites en erreurs GORM (TranslateError), dont les repositories dépendent
+// pour détecter les doublons (gorm.ErrDuplicatedKey). Les requêtes sont journalisées par le logger slog.
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, Logger: logging.NewGormLogger()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", cfg.Driver, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying SQL database: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutes) * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTimeMinutes) * time.Minute)
+	return db, nil
+}
+
+// dialectorFor retourne le dialecte GORM du pilote configuré.
+func dialectorFor(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverSQLite, "":
+		return sqlite.Open(sqliteDSN(cfg)), nil
+	case DriverPostgres:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("database.dsn is required for driver %q", cfg.Driver)
+		}
+		return postgres.Open(cfg.DSN), nil
+	case DriverMySQL:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("database.dsn is required for driver %q", cfg.Driver)
+		}
+		// Les dates doivent être lues en time.Time et stockées en UTC, comme avec les autres pilotes.
+		mysqlCfg, err := gomysql.ParseDSN(cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MySQL DSN: %w", err)
+		}
+		mysqlCfg.ParseTime = true
+		mysqlCfg.Loc = time.UTC
+		return mysql.Open(mysqlCfg.FormatDSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (expected sqlite, postgres or mysql)", cfg.Driver)
+	}
+}
+
+// Describe retourne une description de la base sans secret, pour les logs.
+func Describe(cfg config.DatabaseConfig) string {
+	switch cfg.Driver {
+	case DriverSQLite, "":
+		return "sqlite " + sqliteDSN(cfg)
+	default:
+		return cfg.Driver
+	}
+}
+
+// sqliteDSN retourne la source SQLite configurée ; 'name' reste accepté pour les configurations antérieures à 'dsn'.
+func sqliteDSN(cfg config.DatabaseConfig) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+	return cfg.Name
+}
+
+// Ping vérifie que la base répond. Pour SQLite, le fichier doit aussi exister : une connexion ouverte
+// sur un fichier supprimé continue de fonctionner, mais les écritures sont perdues à la fermeture.
+func Ping(ctx context.Context, sqlDB *sql.DB, cfg config.DatabaseConfig) error {
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	if cfg.Driver != DriverSQLite && cfg.Driver != "" {
+		return nil
+	}
+	path, ok := sqliteFile(sqliteDSN(cfg))
+	if !ok {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("sqlite database file unavailable: %w", err)
+	}
+	return nil
+}
+
+// sqliteFile extrait le chemin du fichier d'une source SQLite ("chemin" ou "file:chemin?options").
+// Retourne false pour une base en mémoire.
+func sqliteFile(dsn string) (string, bool) {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		if strings.Contains(path[i:], "mode=memory") {
+			return "", false
+		}
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" {
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,256 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// TestDatabases applique les migrations, enregistre des clics, calcule les séries temporelles et
+// consulte la liste de blocage sur chaque base supportée, puis annule les migrations.
+//
+// SQLite tourne dans le processus et PostgreSQL est démarré par embedded-postgres (ignoré avec -short,
+// ou si ses binaires ne peuvent être téléchargés). POSTGRES_DSN et MYSQL_DSN désignent à la place une base
+// vide existante ; MySQL n'est testé que si MYSQL_DSN est fourni.
+func TestDatabases(t *testing.T) {
+	for _, driver := range []string{database.DriverSQLite, database.DriverPostgres, database.DriverMySQL} {
+		t.Run(driver, func(t *testing.T) {
+			db := openDatabase(t, driver)
+			testMigrations(t, db)
+		})
+	}
+}
+
+// openDatabase ouvre une base vide du pilote 'driver', ou ignore le test si aucune n'est disponible.
+func openDatabase(t *testing.T, driver string) *gorm.DB {
+	t.Helper()
+	cfg := config.DatabaseConfig{Driver: driver}
+	switch driver {
+	case database.DriverSQLite:
+		cfg.DSN = filepath.Join(t.TempDir(), "test.db")
+	case database.DriverPostgres:
+		cfg.DSN = os.Getenv("POSTGRES_DSN")
+		if cfg.DSN == "" {
+			cfg.DSN = startEmbeddedPostgres(t)
+		}
+	case database.DriverMySQL:
+		cfg.DSN = os.Getenv("MYSQL_DSN")
+		if cfg.DSN == "" {
+			t.Skip("MYSQL_DSN is not set")
+		}
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// startEmbeddedPostgres démarre un serveur PostgreSQL jetable et retourne son DSN.
+func startEmbeddedPostgres(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("embedded PostgreSQL is skipped in short mode")
+	}
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("free port: %v", err)
+	}
+	runtime := t.TempDir()
+	postgres := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V16).
+		Port(port).
+		RuntimePath(runtime).
+		DataPath(filepath.Join(runtime, "data")).
+		Logger(io.Discard).
+		StartTimeout(time.Minute))
+	if err := postgres.Start(); err != nil {
+		t.Skipf("embedded PostgreSQL unavailable: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := postgres.Stop(); err != nil {
+			t.Errorf("stop embedded PostgreSQL: %v", err)
+		}
+	})
+	return fmt.Sprintf("host=127.0.0.1 port=%d user=postgres password=postgres dbname=postgres sslmode=disable", port)
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+func testMigrations(t *testing.T, db *gorm.DB) {
+	ctx := context.Background()
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("migrations.New: %v", err)
+	}
+	migrator = migrator.WithContext(ctx)
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("Up applied no migration: the database must be empty")
+	}
+	rolledBack := false
+	t.Cleanup(func() {
+		if !rolledBack {
+			migrator.Down(len(applied))
+		}
+	})
+	if pending, err := migrator.Pending(); err != nil || len(pending) != 0 {
+		t.Fatalf("Pending after Up = %d migration(s), %v; want none", len(pending), err)
+	}
+
+	t.Run("clicks", func(t *testing.T) { testClicks(t, db) })
+	t.Run("blocklist", func(t *testing.T) { testBlocklist(t, db) })
+
+	rolledBack = true
+	if _, err := migrator.Down(len(applied)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("migration %04d_%s is still applied after Down", status.Version, status.Name)
+		}
+	}
+	for _, table := range []string{"links", "clicks", "link_healths", "link_changes", "api_keys"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s still exists after Down", table)
+		}
+	}
+}
+
+// testClicks vérifie l'écriture de clics aux colonnes pleines et l'agrégation par heure, jour et semaine.
+func testClicks(t *testing.T, db *gorm.DB) {
+	ctx := context.Background()
+	links := repository.NewLinkRepository(db)
+	clicks := repository.NewClickRepository(db)
+
+	link := &models.Link{ShortCode: "dbtest", LongURL: "https://example.com/"}
+	if err := links.CreateLink(ctx, link); err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+
+	// Le 9 mars 2025 est un dimanche : le premier clic appartient à la semaine précédente.
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, time.UTC) }
+	batch := []*models.Click{
+		{LinkID: link.ID, Timestamp: at(9, 23, 30), UserAgent: strings.Repeat("é", 255)},
+		{LinkID: link.ID, Timestamp: at(10, 0, 10), Referrer: "https://referrer.example/" + strings.Repeat("b", 2048-25)},
+		{LinkID: link.ID, Timestamp: at(10, 0, 50), ReferrerHost: strings.Repeat("h", 255)},
+		{LinkID: link.ID, Timestamp: at(10, 1, 5)},
+	}
+	if err := clicks.CreateClicks(ctx, batch); err != nil {
+		t.Fatalf("CreateClicks: %v", err)
+	}
+
+	total, err := clicks.CountClicksByLinkID(ctx, link.ID)
+	if err != nil || total != len(batch) {
+		t.Fatalf("CountClicksByLinkID = %d, %v; want %d", total, err, len(batch))
+	}
+
+	tests := []struct {
+		interval string
+		want     []models.ClickBucket
+	}{
+		{repository.IntervalHour, []models.ClickBucket{{Start: at(9, 23, 0), Count: 1}, {Start: at(10, 0, 0), Count: 2}, {Start: at(10, 1, 0), Count: 1}}},
+		{repository.IntervalDay, []models.ClickBucket{{Start: at(9, 0, 0), Count: 1}, {Start: at(10, 0, 0), Count: 3}}},
+		{repository.IntervalWeek, []models.ClickBucket{{Start: at(3, 0, 0), Count: 1}, {Start: at(10, 0, 0), Count: 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			got, err := clicks.CountClicksByInterval(ctx, link.ID, at(1, 0, 0), at(15, 0, 0), tt.interval)
+			if err != nil {
+				t.Fatalf("CountClicksByInterval: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("CountClicksByInterval = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || got[i].Count != tt.want[i].Count {
+					t.Errorf("bucket %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Les bornes sont exclues à droite : le dernier clic sort de l'intervalle.
+	got, err := clicks.CountClicksByInterval(ctx, link.ID, at(10, 0, 0), at(10, 1, 0), repository.IntervalDay)
+	if err != nil || len(got) != 1 || got[0].Count != 2 {
+		t.Errorf("CountClicksByInterval over [00:00, 01:00) = %v, %v; want 2 clicks", got, err)
+	}
+}
+
+// testBlocklist vérifie la correspondance des entrées de la liste de blocage en base et en mémoire,
+// y compris pour un préfixe contenant des caractères multi-octets.
+func testBlocklist(t *testing.T, db *gorm.DB) {
+	ctx := context.Background()
+	blocklist := services.NewBlocklistService(repository.NewBlocklistRepository(db))
+	list := "evil.example\nhttps://phish.example/login\nhttps://café.example/menu/été\n"
+	if _, err := blocklist.Import(ctx, strings.NewReader(list), "test", false); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	snapshot, err := blocklist.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://evil.example/", true},
+		{"https://www.evil.example/page", true},
+		{"https://phish.example/login?next=/", true},
+		{"https://phish.example/logout", false},
+		{"https://café.example/menu/été/dessert", true},
+		{"https://café.example/menu/hiver", false},
+		{"https://example.com/", false},
+	}
+	for _, tt := range tests {
+		for name, check := range map[string]func(context.Context, ...string) error{
+			"database": blocklist.Check,
+			"snapshot": snapshot.Check,
+		} {
+			err := check(ctx, tt.url)
+			if blocked := errors.Is(err, services.ErrDestinationBlocked); blocked != tt.blocked || (err != nil && !blocked) {
+				t.Errorf("%s check of %s = %v, want blocked=%v", name, tt.url, err, tt.blocked)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormSlowThreshold est la durée au-delà de laquelle une requête GORM est journalisée comme lente.
+const gormSlowThreshold = 200 * time.Millisecond
+
+// GormLogger remplace le logger par défaut de GORM (écrit sur la sortie standard) par le logger slog :
+// requêtes en erreur au niveau error, requêtes lentes au niveau warn, autres requêtes au niveau debug.
+// Une recherche sans résultat (gorm.ErrRecordNotFound) n'est pas une erreur : les repositories la traitent.
+// Les valeurs des paramètres ne sont pas journalisées (adresses e-mail, empreintes de clés API...).
+type GormLogger struct {
+	level logger.LogLevel
+}
+
+// NewGormLogger crée un GormLogger ; le niveau effectif est celui du logger slog.
+func NewGormLogger() *GormLogger {
+	return &GormLogger{level: logger.Info}
+}
+
+// LogMode retourne un logger limité au niveau 'level' (db.Debug() demande logger.Info).
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &GormLogger{level: level}
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+// Trace journalise une requête exécutée, selon son résultat et sa durée.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "Requête SQL en erreur"
+	case elapsed > gormSlowThreshold && l.level >= logger.Warn:
+		level, msg = slog.LevelWarn, "Requête SQL lente"
+	case l.level >= logger.Info:
+		level, msg = slog.LevelDebug, "Requête SQL"
+	default:
+		return
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{"component", "gorm", "duration", elapsed, "rows", rows, "sql", sql}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.Log(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter retire les valeurs des paramètres de la requête journalisée, qui garde ses marqueurs (?, $1...).
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
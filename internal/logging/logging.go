@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader est l'en-tête HTTP portant l'identifiant de requête, reçu du client ou généré.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// Setup installe le logger par défaut (slog et log standard) décrit par la configuration :
+// niveau debug, info, warn ou error, et format text ou json.
+func Setup(cfg config.LoggingConfig) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "text", "":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (expected text or json)", cfg.Format)
+	}
+
+	// Les appels restants au package log sont aussi redirigés vers ce logger, au niveau info.
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// Fatal journalise 'msg' au niveau error puis termine le processus.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// WithRequestID retourne un contexte portant l'identifiant de requête 'id'.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retourne l'identifiant de requête porté par 'ctx', ou "" s'il n'y en a pas.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID génère un identifiant de requête aléatoire (16 caractères hexadécimaux).
+func NewRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID indique si un identifiant reçu d'un client peut être repris tel quel :
+// 1 à 128 caractères parmi lettres, chiffres, '-', '_', '.' et ':'.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("-_.:", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// contextHandler ajoute aux enregistrements journalisés avec un contexte l'attribut request_id,
+// ainsi que trace_id et span_id lorsque le contexte porte un span tracé.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "urlshortener"
+
+// Registry regroupe les métriques du service, exposées par Handler au format texte Prometheus.
+var Registry = prometheus.NewRegistry()
+
+// Issues des événements de clic lorsque le channel des workers est saturé.
+const (
+	ClickJournaled = "journaled" // Écrit dans le journal, rejoué au prochain démarrage
+	ClickDropped   = "dropped"   // Perdu : l'écriture dans le journal a aussi échoué
+)
+
+var (
+	Redirects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Requêtes de redirection traitées, par code de statut HTTP.",
+	}, []string{"status"})
+
+	LinksCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "links_created_total",
+		Help:      "Liens courts créés.",
+	})
+
+	ClickChannelFull = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_events_channel_full_total",
+		Help:      "Événements de clic refusés par le channel saturé, par issue (journaled ou dropped).",
+	}, []string{"outcome"})
+
+	ClickInsertErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_insert_errors_total",
+		Help:      "Échecs d'écriture d'un lot de clics par les workers (chaque tentative compte).",
+	})
+
+	ClickInsertDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "click_insert_duration_seconds",
+		Help:      "Durée d'écriture d'un lot de clics par les workers.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+	})
+
+	MonitorChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "monitor_checks_total",
+		Help:      "Contrôles de destination effectués par le moniteur, par résultat (accessible ou inaccessible).",
+	}, []string{"outcome"})
+
+	MonitorCheckDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "monitor_check_duration_seconds",
+		Help:      "Durée d'un contrôle de destination par le moniteur.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	MonitorPassDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "monitor_pass_duration_seconds",
+		Help:      "Durée d'une passe complète du moniteur.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8),
+	})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durée des requêtes GORM, par opération (create, query, update, delete, row, raw).",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Redirects, LinksCreated, ClickChannelFull, ClickInsertErrors, ClickInsertDuration,
+		MonitorChecks, MonitorCheckDuration, MonitorPassDuration, DBQueryDuration,
+	)
+}
+
+// RegisterClickChannel expose le remplissage et la capacité du channel des événements de clic.
+// À appeler une seule fois, par le serveur qui crée le channel.
+func RegisterClickChannel(clickChan chan models.ClickEvent) {
+	Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "click_channel_depth",
+			Help:      "Événements de clic en attente dans le channel des workers.",
+		}, func() float64 { return float64(len(clickChan)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "click_channel_capacity",
+			Help:      "Capacité du channel des événements de clic.",
+		}, func() float64 { return float64(cap(clickChan)) }),
+	)
+}
+
+// RegisterLinkCache expose les compteurs du cache des liens, lus par 'stats' à chaque collecte.
+// À appeler une seule fois, par le serveur qui crée le cache.
+func RegisterLinkCache(stats func() repository.LinkCacheStats) {
+	Registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "link_cache_hits_total",
+			Help:      "Lectures de liens servies par le cache.",
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "link_cache_negative_hits_total",
+			Help:      "Lectures de codes inconnus servies par le cache négatif (comprises dans link_cache_hits_total).",
+		}, func() float64 { return float64(stats().NegativeHits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "link_cache_misses_total",
+			Help:      "Lectures de liens absents ou expirés du cache, lus en base.",
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "link_cache_evictions_total",
+			Help:      "Liens évincés du cache faute de place.",
+		}, func() float64 { return float64(stats().Evictions) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "link_cache_size",
+			Help:      "Liens actuellement en cache.",
+		}, func() float64 { return float64(stats().Size) }),
+	)
+}
+
+// Handler sert les métriques au format texte Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0001 crée les tables de l'application avec toutes leurs colonnes et index : clés d'API, liens, clics,
+// séquences, état et historique des contrôles, abonnements, règles de domaine, liste de blocage et
+// signalements. Les structures sont figées ici : les modèles de internal/models peuvent évoluer sans
+// changer ce que fait cette migration. Sur une base où ces tables existent déjà, seuls les colonnes et
+// index manquants sont ajoutés.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up:      initialSchemaUp,
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("abuse_reports", "blocklist_entries", "domain_rules", "link_subscriptions",
+				"health_checks", "link_healths", "clicks", "links", "api_keys", "sequences")
+		},
+	})
+}
+
+func initialSchemaUp(tx *gorm.DB) error {
+	type apiKey struct {
+		ID         uint   `gorm:"primaryKey"`
+		Name       string `gorm:"size:100;not null"`
+		Prefix     string `gorm:"size:16;index;not null"`
+		KeyHash    string `gorm:"size:64;uniqueIndex;not null"`
+		CreatedAt  time.Time
+		LastUsedAt *time.Time
+		RevokedAt  *time.Time
+	}
+	type link struct {
+		ID             uint   `gorm:"primaryKey"`
+		ShortCode      string `gorm:"unique;index;size:32;not null"`
+		LongURL        string `gorm:"not null"`
+		CreatedAt      time.Time
+		OwnerID        *uint          `gorm:"index"`
+		Owner          *apiKey        `gorm:"foreignKey:OwnerID"`
+		ExpiresAt      *time.Time     `gorm:"index"`
+		MaxClicks      int            `gorm:"not null;default:0"`
+		ExpiredAt      *time.Time     `gorm:"index"`
+		DeadLinkPolicy string         `gorm:"size:16"`
+		FallbackURL    string         `gorm:"size:2048"`
+		BlockedAt      *time.Time     `gorm:"index"`
+		BlockedReason  string         `gorm:"size:255"`
+		DisabledAt     *time.Time     `gorm:"index"`
+		DisabledReason string         `gorm:"size:255"`
+		DeletedAt      gorm.DeletedAt `gorm:"index"`
+	}
+	type click struct {
+		ID           uint `gorm:"primaryKey"`
+		LinkID       uint `gorm:"index"`
+		Link         link `gorm:"foreignKey:LinkID"`
+		Timestamp    time.Time
+		UserAgent    string `gorm:"size:255"`
+		IPAddress    string `gorm:"size:50"`
+		Referrer     string `gorm:"size:2048"`
+		ReferrerHost string `gorm:"size:255"`
+		Browser      string `gorm:"size:50"`
+		OS           string `gorm:"size:50"`
+		Device       string `gorm:"size:20"`
+	}
+	type sequence struct {
+		Name  string `gorm:"primaryKey;size:50"`
+		Value uint64 `gorm:"not null"`
+	}
+	type linkHealth struct {
+		LinkID              uint `gorm:"primaryKey;autoIncrement:false"`
+		Link                link `gorm:"foreignKey:LinkID"`
+		Accessible          bool `gorm:"index"`
+		StatusCode          int
+		LatencyMs           int64
+		Error               string   `gorm:"size:500"`
+		FinalURL            string   `gorm:"size:2048"`
+		RedirectChain       []string `gorm:"type:text;serializer:json"`
+		DomainChanged       bool
+		SoftNotFound        bool
+		CertExpiresAt       *time.Time
+		CertIssuer          string `gorm:"size:255"`
+		CertHostnameValid   bool
+		CertError           string `gorm:"size:255"`
+		ConsecutiveFailures int
+		LastCheckedAt       time.Time
+		LastChangedAt       time.Time
+	}
+	type healthCheck struct {
+		ID            uint      `gorm:"primaryKey"`
+		LinkID        uint      `gorm:"index:idx_health_checks_link_checked,priority:1"`
+		CheckedAt     time.Time `gorm:"index:idx_health_checks_link_checked,priority:2"`
+		Accessible    bool
+		StatusCode    int
+		LatencyMs     int64
+		Error         string   `gorm:"size:500"`
+		FinalURL      string   `gorm:"size:2048"`
+		RedirectChain []string `gorm:"type:text;serializer:json"`
+		DomainChanged bool
+		SoftNotFound  bool
+	}
+	type linkSubscription struct {
+		ID        uint   `gorm:"primaryKey"`
+		LinkID    uint   `gorm:"index;not null"`
+		Link      link   `gorm:"foreignKey:LinkID"`
+		Channel   string `gorm:"size:16;not null"`
+		Target    string `gorm:"size:2048;not null"`
+		Secret    string `gorm:"size:128"`
+		CreatedAt time.Time
+	}
+	type domainRule struct {
+		ID        uint   `gorm:"primaryKey"`
+		Domain    string `gorm:"size:255;uniqueIndex;not null"`
+		Action    string `gorm:"size:8;not null"`
+		Reason    string `gorm:"size:255"`
+		CreatedAt time.Time
+	}
+	type blocklistEntry struct {
+		ID        uint   `gorm:"primaryKey"`
+		Kind      string `gorm:"size:8;not null;uniqueIndex:idx_blocklist_entry"`
+		Value     string `gorm:"size:512;not null;uniqueIndex:idx_blocklist_entry"`
+		Source    string `gorm:"size:64;not null;uniqueIndex:idx_blocklist_entry"`
+		CreatedAt time.Time
+	}
+	type abuseReport struct {
+		ID            uint   `gorm:"primaryKey"`
+		LinkID        uint   `gorm:"index;not null"`
+		Link          link   `gorm:"foreignKey:LinkID"`
+		Category      string `gorm:"size:16;not null"`
+		Details       string `gorm:"size:2000"`
+		ReporterEmail string `gorm:"size:254"`
+		ReporterIP    string `gorm:"size:64"`
+		Status        string `gorm:"size:16;index;not null"`
+		Resolution    string `gorm:"size:255"`
+		CreatedAt     time.Time
+		ResolvedAt    *time.Time
+	}
+
+	return tx.AutoMigrate(&apiKey{}, &link{}, &click{}, &sequence{}, &linkHealth{}, &healthCheck{},
+		&linkSubscription{}, &domainRule{}, &blocklistEntry{}, &abuseReport{})
+}
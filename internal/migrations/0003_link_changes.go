@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0003 crée la table des modifications de liens, relue par les serveurs pour invalider leur cache.
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "link_changes",
+		Up: func(tx *gorm.DB) error {
+			type linkChange struct {
+				ID        uint `gorm:"primaryKey"`
+				LinkID    uint
+				ShortCode string    `gorm:"size:32"`
+				CreatedAt time.Time `gorm:"index"`
+			}
+			return tx.AutoMigrate(&linkChange{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("link_changes")
+		},
+	})
+}
@@ -0,0 +1,179 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrIrreversible est retournée par Down pour une migration sans fonction de retour arrière.
+var ErrIrreversible = errors.New("migration cannot be rolled back")
+
+// Migration est une évolution numérotée du schéma. Up et Down s'exécutent dans une transaction
+// (MySQL valide toutefois implicitement chaque instruction DDL).
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // nil = migration irréversible
+}
+
+// SchemaMigration est la ligne enregistrée dans 'schema_migrations' pour chaque migration appliquée.
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+// MigrationStatus décrit l'état d'une migration connue du binaire ou enregistrée en base.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // nil = en attente
+	Unknown   bool       // Appliquée en base mais absente de ce binaire
+}
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// goMigrations sont les migrations écrites en Go, enregistrées par register() depuis les fichiers NNNN_*.go.
+var goMigrations []Migration
+
+func register(m Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+// sqlFileName reconnaît "0002_nom.up.sql", "0002_nom.down.sql" et leurs variantes propres à un dialecte
+// ("0002_nom.mysql.up.sql"), prioritaires sur la version générique.
+var sqlFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+?)(?:\.(sqlite|postgres|mysql))?\.(up|down)\.sql$`)
+
+// Load retourne toutes les migrations (Go et SQL embarquées) pour le dialecte 'dialect', triées par version.
+func Load(dialect string) ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+	for _, m := range goMigrations {
+		if _, exists := byVersion[m.Version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		copied := m
+		byVersion[m.Version] = &copied
+	}
+
+	sqlMigrations, err := loadSQL(dialect)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range sqlMigrations {
+		if _, exists := byVersion[m.Version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		byVersion[m.Version] = m
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %d (%s) has no up step", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// loadSQL construit les migrations à partir des fichiers SQL embarqués.
+func loadSQL(dialect string) (map[int]*Migration, error) {
+	entries, err := fs.ReadDir(sqlFiles, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	migrations := make(map[int]*Migration)
+	// Pour chaque version et direction, indique si le fichier retenu est propre au dialecte :
+	// celui-ci l'emporte sur le fichier générique.
+	specific := make(map[string]bool)
+	for _, entry := range entries {
+		match := sqlFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		name, fileDialect, direction := match[2], match[3], match[4]
+		if fileDialect != "" && fileDialect != dialect {
+			continue
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		key := match[1] + "." + direction
+		if isSpecific, seen := specific[key]; seen && (isSpecific || fileDialect == "") {
+			continue
+		}
+		specific[key] = fileDialect != ""
+
+		content, err := sqlFiles.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		step := execSQL(string(content))
+		if direction == "up" {
+			m.Up = step
+		} else {
+			m.Down = step
+		}
+	}
+	return migrations, nil
+}
+
+// execSQL retourne une étape exécutant les instructions du script une à une (tous les pilotes
+// n'acceptent pas plusieurs instructions par requête).
+func execSQL(script string) func(tx *gorm.DB) error {
+	statements := splitStatements(script)
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("%w (statement: %s)", err, statement)
+			}
+		}
+		return nil
+	}
+}
+
+// splitStatements découpe un script SQL en instructions. Une instruction se termine par un ';'
+// en fin de ligne ; les lignes de commentaire ("--") et les lignes vides sont ignorées.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(strings.TrimRight(line, " \t\r"))
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
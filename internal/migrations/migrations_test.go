@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "empty",
+			script: "",
+			want:   nil,
+		},
+		{
+			name:   "comments and blank lines only",
+			script: "-- commentaire\n\n   -- indenté\n",
+			want:   nil,
+		},
+		{
+			name:   "single statement",
+			script: "CREATE INDEX idx ON clicks (link_id);\n",
+			want:   []string{"CREATE INDEX idx ON clicks (link_id)"},
+		},
+		{
+			name:   "several statements with comments",
+			script: "-- premier\nDROP INDEX a;\n\n-- second\nDROP INDEX b;\n",
+			want:   []string{"DROP INDEX a", "DROP INDEX b"},
+		},
+		{
+			name:   "multi-line statement",
+			script: "CREATE TABLE t (\n  id INTEGER,  \n  -- colonne\n  name TEXT\n);\n",
+			want:   []string{"CREATE TABLE t (\n  id INTEGER,\n  name TEXT\n)"},
+		},
+		{
+			name:   "semicolon inside a line does not end the statement",
+			script: "SELECT 'a; b'\nFROM t;\n",
+			want:   []string{"SELECT 'a; b'\nFROM t"},
+		},
+		{
+			name:   "trailing statement without semicolon",
+			script: "DROP INDEX a;\nDROP INDEX b",
+			want:   []string{"DROP INDEX a", "DROP INDEX b"},
+		},
+		{
+			name:   "windows line endings",
+			script: "DROP INDEX a;\r\nDROP INDEX b;\r\n",
+			want:   []string{"DROP INDEX a", "DROP INDEX b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"sqlite", "postgres", "mysql"} {
+		t.Run(dialect, func(t *testing.T) {
+			migrations, err := Load(dialect)
+			if err != nil {
+				t.Fatalf("Load(%q): %v", dialect, err)
+			}
+			if len(migrations) == 0 {
+				t.Fatal("no migration loaded")
+			}
+			for i, m := range migrations {
+				if m.Version != i+1 {
+					t.Errorf("migration %d has version %d, want consecutive versions from 1", i, m.Version)
+				}
+				if m.Up == nil || m.Down == nil {
+					t.Errorf("migration %04d_%s must have up and down steps", m.Version, m.Name)
+				}
+			}
+		})
+	}
+}
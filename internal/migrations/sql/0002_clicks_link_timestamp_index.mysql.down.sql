@@ -0,0 +1 @@
+DROP INDEX idx_clicks_link_timestamp ON clicks;
@@ -0,0 +1,2 @@
+-- MySQL n'accepte pas les guillemets doubles autour des identifiants (hors mode ANSI_QUOTES).
+CREATE INDEX idx_clicks_link_timestamp ON clicks (link_id, `timestamp`);
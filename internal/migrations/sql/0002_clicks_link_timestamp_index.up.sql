@@ -0,0 +1,2 @@
+-- Index composite pour les statistiques temporelles (CountClicksByInterval filtre par lien et par date).
+CREATE INDEX idx_clicks_link_timestamp ON clicks (link_id, "timestamp");
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Catégories de signalement d'abus.
+const (
+	AbusePhishing = "phishing"
+	AbuseMalware  = "malware"
+	AbuseSpam     = "spam"
+	AbuseIllegal  = "illegal"
+	AbuseOther    = "other"
+)
+
+// États d'un signalement.
+const (
+	ReportOpen      = "open"      // À examiner
+	ReportDismissed = "dismissed" // Rejeté, le lien reste actif
+	ReportActioned  = "actioned"  // Le lien a été désactivé
+)
+
+// AbuseReport est un signalement public d'un lien court malveillant, examiné par les administrateurs.
+type AbuseReport struct {
+	ID       uint   `gorm:"primaryKey"`
+	LinkID   uint   `gorm:"index;not null"`
+	Link     Link   `gorm:"foreignKey:LinkID"`
+	Category string `gorm:"size:16;not null"`
+	Details  string `gorm:"size:2000"`
+	// ReporterEmail permet de recontacter l'auteur du signalement (facultatif).
+	ReporterEmail string `gorm:"size:254"`
+	ReporterIP    string `gorm:"size:64"`
+	Status        string `gorm:"size:16;index;not null"`
+	// Resolution est le motif indiqué par l'administrateur lors du traitement.
+	Resolution string `gorm:"size:255"`
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIKey représente une clé d'accès à l'API REST.
+// Seule l'empreinte SHA-256 de la clé est stockée ; la clé en clair n'est affichée qu'à sa création.
+type APIKey struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:100;not null"`
+	// Prefix contient les premiers caractères de la clé, pour l'identifier sans la révéler.
+	Prefix     string `gorm:"size:16;index;not null"`
+	KeyHash    string `gorm:"size:64;uniqueIndex;not null"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	// RevokedAt est renseigné lorsque la clé est révoquée ; elle est alors refusée par l'API.
+	RevokedAt *time.Time
+}
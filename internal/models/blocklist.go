@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Actions des règles de domaine.
+const (
+	DomainRuleAllow = "allow" // Dès qu'une règle "allow" existe, seuls ces domaines sont acceptés
+	DomainRuleDeny  = "deny"  // Domaine interdit
+)
+
+// Types d'entrées de la liste de blocage locale.
+const (
+	BlocklistHost   = "host"   // Nom d'hôte (et ses sous-domaines)
+	BlocklistPrefix = "prefix" // Préfixe d'URL, sans le schéma (ex: "evil.example/phish")
+	BlocklistHash   = "hash"   // Empreinte SHA-256 (hexadécimal) d'un nom d'hôte ou d'une URL
+)
+
+// DomainRule est une règle d'autorisation ou d'interdiction d'un domaine de destination,
+// gérée par les administrateurs. Elle s'applique aussi à tous les sous-domaines.
+type DomainRule struct {
+	ID        uint   `gorm:"primaryKey"`
+	Domain    string `gorm:"size:255;uniqueIndex;not null"`
+	Action    string `gorm:"size:8;not null"`
+	Reason    string `gorm:"size:255"`
+	CreatedAt time.Time
+}
+
+// BlocklistEntry est une entrée importée d'un fichier de liste de blocage (URLs malveillantes).
+type BlocklistEntry struct {
+	ID        uint   `gorm:"primaryKey"`
+	Kind      string `gorm:"size:8;not null;uniqueIndex:idx_blocklist_entry"`
+	Value     string `gorm:"size:512;not null;uniqueIndex:idx_blocklist_entry"`
+	Source    string `gorm:"size:64;not null;uniqueIndex:idx_blocklist_entry"` // Nom du fichier importé
+	CreatedAt time.Time
+}
@@ -11,6 +11,13 @@ type Click struct {
 	Timestamp time.Time
 	UserAgent string `gorm:"size:255"`
 	IPAddress string `gorm:"size:50"`
+	// Referrer est l'en-tête Referer complet ; ReferrerHost n'en garde que l'hôte pour les agrégations.
+	Referrer     string `gorm:"size:2048"`
+	ReferrerHost string `gorm:"size:255"`
+	// Browser, OS et Device sont déduits du User-Agent par les workers.
+	Browser string `gorm:"size:50"`
+	OS      string `gorm:"size:50"`
+	Device  string `gorm:"size:20"`
 }
 
 type ClickEvent struct {
@@ -18,4 +25,23 @@ type ClickEvent struct {
 	Timestamp time.Time
 	UserAgent string
 	IP        string
+	Referrer  string
+	// RequestID est l'identifiant de la requête de redirection, repris dans les logs des workers.
+	RequestID string
+	// TraceContext porte le contexte de trace de la redirection (en-têtes W3C), auquel le span
+	// d'écriture du lot par les workers est relié.
+	TraceContext map[string]string
+}
+
+// ClickBucket représente le nombre de clics enregistrés dans un intervalle de temps
+// commençant à Start (en UTC).
+type ClickBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// ClickCount représente le nombre de clics partageant une même valeur (référent, navigateur, ...).
+type ClickCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
 }
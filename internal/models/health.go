@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// LinkHealth est l'état courant de la destination d'un lien, tel que vu par le dernier contrôle du moniteur.
+type LinkHealth struct {
+	LinkID     uint `gorm:"primaryKey;autoIncrement:false"`
+	Link       Link `gorm:"foreignKey:LinkID"`
+	Accessible bool `gorm:"index"`
+	StatusCode int
+	LatencyMs  int64
+	Error      string `gorm:"size:500"`
+	// FinalURL est la destination atteinte après les redirections ; RedirectChain liste les URLs intermédiaires.
+	FinalURL      string   `gorm:"size:2048"`
+	RedirectChain []string `gorm:"type:text;serializer:json"`
+	// DomainChanged signale une destination finale hors du domaine de l'URL longue (parking, page de connexion...).
+	DomainChanged bool
+	// SoftNotFound signale une page « introuvable » servie avec un code 2xx.
+	SoftNotFound bool
+	// Certificat TLS présenté par la destination finale (CertExpiresAt nil pour une destination HTTP).
+	CertExpiresAt     *time.Time
+	CertIssuer        string `gorm:"size:255"`
+	CertHostnameValid bool
+	CertError         string `gorm:"size:255"` // Échec de vérification du certificat (expiré, autorité inconnue...)
+	// ConsecutiveFailures compte les contrôles en échec depuis le dernier succès.
+	ConsecutiveFailures int
+	LastCheckedAt       time.Time
+	// LastChangedAt est la date du dernier passage d'ACCESSIBLE à INACCESSIBLE (ou inversement).
+	LastChangedAt time.Time
+}
+
+// HealthCheck est l'historique d'un contrôle d'accessibilité de la destination d'un lien.
+type HealthCheck struct {
+	ID            uint      `gorm:"primaryKey"`
+	LinkID        uint      `gorm:"index:idx_health_checks_link_checked,priority:1"`
+	CheckedAt     time.Time `gorm:"index:idx_health_checks_link_checked,priority:2"`
+	Accessible    bool
+	StatusCode    int
+	LatencyMs     int64
+	Error         string   `gorm:"size:500"`
+	FinalURL      string   `gorm:"size:2048"`
+	RedirectChain []string `gorm:"type:text;serializer:json"`
+	DomainChanged bool
+	SoftNotFound  bool
+}
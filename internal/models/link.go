@@ -1,10 +1,69 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Politiques appliquées à la redirection lorsque le moniteur juge la destination hors service.
+const (
+	DeadLinkKeep         = "keep"         // Redirection inchangée
+	DeadLinkInterstitial = "interstitial" // Page d'avertissement avant de poursuivre
+	DeadLinkFallback     = "fallback"     // Redirection vers une URL de repli
+	DeadLinkUnavailable  = "unavailable"  // 503 Service Unavailable
+)
 
 type Link struct {
-	ID        uint      `gorm:"primaryKey"`
-	ShortCode string    `gorm:"unique;index;size:10;not null"`
-	LongURL   string    `gorm:"not null"`
+	ID        uint   `gorm:"primaryKey"`
+	ShortCode string `gorm:"unique;index;size:32;not null"`
+	LongURL   string `gorm:"not null"`
 	CreatedAt time.Time
+	// OwnerID référence la clé d'API qui a créé le lien (nil pour les liens créés en CLI).
+	OwnerID *uint   `gorm:"index"`
+	Owner   *APIKey `gorm:"foreignKey:OwnerID"`
+	// ExpiresAt est la date au-delà de laquelle le lien n'est plus redirigé (nil = jamais).
+	ExpiresAt *time.Time `gorm:"index"`
+	// MaxClicks est le nombre maximal de clics autorisés (0 = illimité).
+	MaxClicks int `gorm:"not null;default:0"`
+	// ExpiredAt est renseigné par le sweeper lorsque le lien a été marqué comme expiré.
+	ExpiredAt *time.Time `gorm:"index"`
+	// DeadLinkPolicy est le comportement de la redirection lorsque la destination est hors service
+	// (vide = politique globale de la configuration).
+	DeadLinkPolicy string `gorm:"size:16"`
+	// FallbackURL remplace la destination hors service avec la politique "fallback" (vide = URL de repli globale).
+	FallbackURL string `gorm:"size:2048"`
+	// BlockedAt est renseigné par le moniteur lorsque la destination figure sur une liste de blocage ;
+	// le lien répond alors 451 jusqu'à ce que la destination n'y figure plus.
+	BlockedAt     *time.Time `gorm:"index"`
+	BlockedReason string     `gorm:"size:255"`
+	// DisabledAt est renseigné lorsqu'un administrateur désactive le lien (signalement d'abus) ;
+	// la redirection affiche alors une page « lien désactivé ».
+	DisabledAt     *time.Time `gorm:"index"`
+	DisabledReason string     `gorm:"size:255"`
+	// DeletedAt active la suppression logique : un lien supprimé n'est plus visible
+	// mais son code court reste réservé et ne sera jamais réattribué.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// IsExpired indique si le lien est expiré à l'instant 'now', compte tenu de son nombre de clics.
+func (l *Link) IsExpired(now time.Time, totalClicks int) bool {
+	if l.ExpiredAt != nil {
+		return true
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return true
+	}
+	return l.MaxClicks > 0 && totalClicks >= l.MaxClicks
+}
+
+// LinkChange signale la modification d'un lien aux serveurs en cours d'exécution, qui retirent le lien de
+// leur cache : les écritures faites par un autre processus (commandes CLI, autres instances) sont ainsi
+// visibles sans attendre l'expiration du cache. Sans code ni identifiant, la modification concerne
+// plusieurs liens (cache à vider).
+type LinkChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	LinkID    uint      // Lien modifié, lorsque seul son identifiant est connu
+	ShortCode string    `gorm:"size:32"`
+	CreatedAt time.Time `gorm:"index"`
 }
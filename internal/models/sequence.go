@@ -0,0 +1,8 @@
+package models
+
+// Sequence est un compteur nommé stocké en base, partagé par toutes les instances du service.
+// Il alimente les stratégies de génération de codes courts basées sur un compteur.
+type Sequence struct {
+	Name  string `gorm:"primaryKey;size:50"`
+	Value uint64 `gorm:"not null"`
+}
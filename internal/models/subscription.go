@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LinkSubscription abonne un destinataire (webhook, Slack ou e-mail) aux notifications du moniteur pour un lien.
+type LinkSubscription struct {
+	ID        uint   `gorm:"primaryKey"`
+	LinkID    uint   `gorm:"index;not null"`
+	Link      Link   `gorm:"foreignKey:LinkID"`
+	Channel   string `gorm:"size:16;not null"`
+	Target    string `gorm:"size:2048;not null"` // URL du webhook ou adresse e-mail
+	Secret    string `gorm:"size:128"`           // Secret de signature HMAC (webhooks uniquement)
+	CreatedAt time.Time
+}
@@ -0,0 +1,231 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/tracing"
+	"github.com/axellelanca/urlshortener/internal/urlguard"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/publicsuffix"
+)
+
+// CheckResult est le résultat d'un contrôle d'accessibilité.
+type CheckResult struct {
+	Accessible bool
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+	// FinalURL est l'URL atteinte après avoir suivi les redirections.
+	FinalURL string
+	// RedirectChain liste les URLs intermédiaires, de l'URL contrôlée (exclue) à FinalURL (exclue).
+	RedirectChain []string
+	// DomainChanged indique que FinalURL n'appartient plus au domaine de l'URL contrôlée.
+	DomainChanged bool
+	// SoftNotFound indique une page « introuvable » servie avec un code 2xx.
+	SoftNotFound bool
+	// Cert décrit le certificat TLS présenté par la destination finale (nil en HTTP ou sans connexion).
+	Cert *CertInfo
+}
+
+// CertInfo résume le certificat TLS d'une destination.
+type CertInfo struct {
+	ExpiresAt     time.Time
+	Issuer        string
+	HostnameValid bool
+	// Err explique l'échec de vérification du certificat (vide s'il est valide).
+	Err string
+}
+
+// errTooManyRedirects interrompt une chaîne de redirections trop longue.
+var errTooManyRedirects = errors.New("too many redirects")
+
+// CompilePatterns compile les motifs de détection des soft-404 (insensibles à la casse).
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid soft 404 pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// checkRedirect est la politique de redirection du client partagé : la chaîne est bornée par 'maxRedirects'
+// et chaque cible doit respecter la politique des destinations (les adresses sont vérifiées à la connexion).
+// Les URLs intermédiaires sont retrouvées après coup via resp.Request (voir redirectChain).
+func checkRedirect(maxRedirects int, destinations *urlguard.Policy) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return errTooManyRedirects
+		}
+		if _, err := destinations.CheckStatic(req.URL.String()); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// checkUrl contrôle l'accessibilité d'une URL : requête HEAD (plus légère), avec repli sur un GET partiel
+// si le serveur refuse HEAD. Le GET est utilisé d'emblée lorsque la détection des soft-404 exige le corps.
+// Les redirections sont suivies ; la chaîne et le changement éventuel de domaine sont relevés.
+func (m *UrlMonitor) checkUrl(ctx context.Context, rawURL string) CheckResult {
+	start := time.Now()
+
+	// Les liens créés avant un durcissement de la politique sont aussi refusés ici.
+	if _, err := m.cfg.Destinations.CheckStatic(rawURL); err != nil {
+		m.logger.WarnContext(ctx, "Destination refusée", "url", rawURL, "error", err)
+		tracing.RecordError(trace.SpanFromContext(ctx), err)
+		return CheckResult{Err: err}
+	}
+
+	var result CheckResult
+	if len(m.cfg.SoftNotFoundPatterns) > 0 {
+		result = m.probe(ctx, http.MethodGet, rawURL)
+	} else {
+		result = m.probe(ctx, http.MethodHead, rawURL)
+		if result.StatusCode == http.StatusMethodNotAllowed || result.StatusCode == http.StatusNotImplemented {
+			result = m.probe(ctx, http.MethodGet, rawURL)
+		}
+	}
+
+	result.Latency = time.Since(start)
+	if result.Err != nil {
+		m.logger.InfoContext(ctx, "Erreur d'accès à l'URL", "url", rawURL, "error", result.Err)
+		tracing.RecordError(trace.SpanFromContext(ctx), result.Err)
+	}
+	return result
+}
+
+// probe envoie une requête 'method' et interprète la réponse finale.
+func (m *UrlMonitor) probe(ctx context.Context, method, rawURL string) CheckResult {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Erreur lors de la création de la requête", "method", method, "url", rawURL, "error", err)
+		return CheckResult{Err: err}
+	}
+	// L'identifiant du contrôle permet à la destination de relier la requête à nos logs.
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	if method == http.MethodGet {
+		// Seul le début de la page est utile : on évite de télécharger des contenus volumineux.
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", m.cfg.MaxBodyBytes-1))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		// Un certificat invalide fait échouer la requête, mais reste utile au suivi (expiration, nom d'hôte).
+		return CheckResult{Err: err, Cert: unverifiedCertInfo(err)}
+	}
+	// Fermer le corps de la réponse pour libérer les ressources
+	defer resp.Body.Close()
+
+	result := CheckResult{
+		StatusCode:    resp.StatusCode,
+		FinalURL:      resp.Request.URL.String(),
+		RedirectChain: redirectChain(resp),
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.Cert = certInfo(resp.TLS.PeerCertificates[0], resp.Request.URL.Hostname(), nil)
+	}
+	result.DomainChanged = registrableDomain(rawURL) != registrableDomain(result.FinalURL)
+
+	// Déterminer l'accessibilité basée sur le code de statut HTTP (2xx ou 3xx).
+	// 416 répond à un GET partiel sur une ressource vide : la ressource existe.
+	result.Accessible = isSuccess(resp.StatusCode) || resp.StatusCode == http.StatusRequestedRangeNotSatisfiable
+
+	if method == http.MethodGet && result.Accessible && len(m.cfg.SoftNotFoundPatterns) > 0 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, m.cfg.MaxBodyBytes))
+		if err != nil {
+			result.Accessible = false
+			result.Err = fmt.Errorf("failed to read body: %w", err)
+			return result
+		}
+		for _, re := range m.cfg.SoftNotFoundPatterns {
+			if re.Match(body) {
+				result.Accessible = false
+				result.SoftNotFound = true
+				result.Err = fmt.Errorf("soft 404: body matches %q", strings.TrimPrefix(re.String(), "(?i)"))
+				break
+			}
+		}
+	}
+	return result
+}
+
+// certInfo résume le certificat 'leaf' présenté pour 'host'. 'verifyErr' est l'erreur de vérification éventuelle.
+func certInfo(leaf *x509.Certificate, host string, verifyErr error) *CertInfo {
+	info := &CertInfo{
+		ExpiresAt:     leaf.NotAfter.UTC(),
+		Issuer:        leaf.Issuer.CommonName,
+		HostnameValid: leaf.VerifyHostname(host) == nil,
+	}
+	if info.Issuer == "" && len(leaf.Issuer.Organization) > 0 {
+		info.Issuer = leaf.Issuer.Organization[0]
+	}
+	if verifyErr != nil {
+		info.Err = verifyErr.Error()
+	}
+	return info
+}
+
+// unverifiedCertInfo extrait le certificat refusé d'une erreur de vérification TLS (nil pour toute autre erreur).
+func unverifiedCertInfo(err error) *CertInfo {
+	var verifyErr *tls.CertificateVerificationError
+	var urlErr *url.Error
+	if !errors.As(err, &verifyErr) || len(verifyErr.UnverifiedCertificates) == 0 || !errors.As(err, &urlErr) {
+		return nil
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return nil
+	}
+	return certInfo(verifyErr.UnverifiedCertificates[0], u.Hostname(), verifyErr.Err)
+}
+
+// isSuccess indique un code de statut 2xx ou 3xx.
+func isSuccess(status int) bool {
+	return status >= 200 && status < 400
+}
+
+// redirectChain reconstitue les URLs intermédiaires à partir de la réponse finale :
+// chaque requête issue d'une redirection référence la réponse qui l'a provoquée.
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req.Response != nil; {
+		req = req.Response.Request
+		chain = append([]string{req.URL.String()}, chain...)
+	}
+	if len(chain) < 2 {
+		return nil
+	}
+	return chain[1:] // La première est l'URL contrôlée
+}
+
+// registrableDomain retourne le domaine enregistrable d'une URL (ex: "example.co.uk" pour "www.example.co.uk"),
+// ou son hôte s'il ne peut pas être déterminé (adresse IP, localhost).
+func registrableDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter espace les requêtes adressées à un même hôte d'au moins 'interval',
+// pour ne pas surcharger un domaine qui héberge beaucoup de destinations.
+type hostLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time // Prochain créneau libre pour chaque hôte
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait réserve le prochain créneau de 'host' et attend qu'il arrive.
+// Retourne l'erreur du contexte s'il est annulé pendant l'attente.
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Reset oublie les créneaux passés, pour que la map ne grossisse pas indéfiniment.
+func (l *hostLimiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for host, slot := range l.next {
+		if slot.Before(now) {
+			delete(l.next, host)
+		}
+	}
+}
+
+// hostOf retourne l'hôte (en minuscules, sans port) d'une URL, ou l'URL entière si elle est invalide.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -1,121 +1,567 @@
 package monitor
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"regexp"
+	"strings"
 	"sync" // Pour protéger l'accès concurrentiel à knownStates
+	"sync/atomic"
 	"time"
 
-	_ "github.com/axellelanca/urlshortener/internal/models"   // Importe les modèles de liens
-	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
+	"github.com/axellelanca/urlshortener/internal/logging"    // Pour identifier chaque contrôle dans les logs et les requêtes envoyées
+	"github.com/axellelanca/urlshortener/internal/metrics"    // Pour exposer les résultats et durées des contrôles
+	"github.com/axellelanca/urlshortener/internal/models"     // Importe les modèles de liens
+	"github.com/axellelanca/urlshortener/internal/notifier"   // Pour notifier les changements d'état
+	"github.com/axellelanca/urlshortener/internal/repository" // Importe les repositories de liens et d'état
+	"github.com/axellelanca/urlshortener/internal/services"   // Pour revérifier les destinations avec la liste de blocage
+	"github.com/axellelanca/urlshortener/internal/textutil"   // Pour borner les textes stockés en base
+	"github.com/axellelanca/urlshortener/internal/tracing"    // Pour tracer les passes et les contrôles
+	"github.com/axellelanca/urlshortener/internal/urlguard"   // Pour refuser les destinations internes (SSRF)
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// maxErrorLength borne la taille des messages d'erreur stockés en base.
+const maxErrorLength = 500
+
+// maxURLLength est la taille de la colonne final_url : une URL plus longue est tronquée.
+const maxURLLength = 2048
+
+// Config règle la fréquence et la charge des contrôles du moniteur.
+type Config struct {
+	Interval        time.Duration // Intervalle entre deux passes (ex: 5 minutes)
+	Retention       time.Duration // Durée de conservation de l'historique des contrôles
+	Concurrency     int           // Nombre de contrôles menés en parallèle
+	RequestTimeout  time.Duration // Délai maximal d'un contrôle
+	PerHostInterval time.Duration // Délai minimal entre deux requêtes vers un même hôte
+	MaxRedirects    int           // Nombre maximal de redirections suivies
+	MaxBodyBytes    int64         // Taille maximale lue lors d'un GET partiel
+	// Destinations refuse les destinations internes (SSRF), vérifiées à chaque connexion et redirection.
+	Destinations *urlguard.Policy
+	// Blocklist revérifie les destinations à chaque passe : un lien dont la destination est devenue
+	// interdite est bloqué (et n'est plus contrôlé), puis débloqué lorsqu'elle ne l'est plus.
+	Blocklist *services.BlocklistService
+	// CertExpiryWarning est le délai avant expiration d'un certificat TLS à partir duquel on alerte (0 = désactivé).
+	CertExpiryWarning time.Duration
+	// SoftNotFoundPatterns détecte les pages « introuvables » servies avec un code 2xx (vide = désactivé).
+	SoftNotFoundPatterns []*regexp.Regexp
+}
+
 // UrlMonitor gère la surveillance périodique des URLs longues.
+// Chaque contrôle est enregistré en base : l'historique et l'état courant survivent ainsi aux redémarrages.
 type UrlMonitor struct {
-	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
-	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
-	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
-	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
+	linkRepo    repository.LinkRepository   // Pour récupérer les URLs à surveiller
+	healthRepo  repository.HealthRepository // Pour persister l'état courant et l'historique des contrôles
+	dispatcher  *notifier.Dispatcher        // Pour notifier les changements d'état (webhooks, e-mails)
+	cfg         Config
+	client      *http.Client                // Client partagé par tous les contrôles (connexions réutilisées)
+	limiter     *hostLimiter                // Limite le débit de requêtes par hôte de destination
+	running     atomic.Bool                 // Vrai tant qu'une passe de vérification est en cours
+	lastBeat    atomic.Int64                // Dernière activité de la boucle (UnixNano), lue par la sonde de disponibilité
+	lastPass    atomic.Int64                // Fin de la dernière passe complète (UnixNano)
+	knownStates map[uint]*models.LinkHealth // État connu de chaque URL: map[LinkID]état courant
+	knownURLs   map[uint]string             // Destination sur laquelle porte chaque état connu: map[LinkID]URL longue
+	mu          sync.Mutex                  // Mutex pour protéger l'accès concurrentiel à knownStates et knownURLs
+	logger      *slog.Logger                // Logger portant l'attribut component=monitor
 }
 
-// TODO finir cette fonction
 // NewUrlMonitor crée et retourne une nouvelle instance de UrlMonitor.
-// Attention: retourne un pointeur
-func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
+func NewUrlMonitor(linkRepo repository.LinkRepository, healthRepo repository.HealthRepository, dispatcher *notifier.Dispatcher, cfg Config) *UrlMonitor {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 5 * time.Second
+	}
+	if cfg.MaxRedirects < 0 {
+		cfg.MaxRedirects = 0
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 64 << 10
+	}
 	return &UrlMonitor{
 		linkRepo:    linkRepo,
-		interval:    interval,
-		knownStates: make(map[uint]bool),
+		healthRepo:  healthRepo,
+		dispatcher:  dispatcher,
+		cfg:         cfg,
+		client:      newCheckClient(cfg),
+		limiter:     newHostLimiter(cfg.PerHostInterval),
+		knownStates: make(map[uint]*models.LinkHealth),
+		knownURLs:   make(map[uint]string),
+		logger:      slog.Default().With("component", "monitor"),
+	}
+}
+
+// newCheckClient construit le client HTTP partagé des contrôles : connexions conservées entre deux passes,
+// délais de connexion bornés et nombre de connexions par hôte limité au parallélisme du moniteur.
+func newCheckClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   cfg.RequestTimeout,
+		KeepAlive: 30 * time.Second,
+		// L'adresse réellement contactée est vérifiée après résolution DNS : un domaine public
+		// qui se résout (ou est redirigé) vers une adresse interne est refusé.
+		Control: cfg.Destinations.Control,
+	}
+	transport := &http.Transport{
+		// Pas de proxy : la connexion se ferait vers le proxy et échapperait au contrôle des adresses.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          cfg.Concurrency * 2,
+		MaxIdleConnsPerHost:   2,
+		MaxConnsPerHost:       cfg.Concurrency,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   cfg.RequestTimeout,
+		ResponseHeaderTimeout: cfg.RequestTimeout,
+		ForceAttemptHTTP2:     true,
+	}
+	return &http.Client{
+		// Chaque requête (redirections comprises) est tracée et porte l'en-tête traceparent.
+		Transport:     otelhttp.NewTransport(transport),
+		Timeout:       cfg.RequestTimeout,
+		CheckRedirect: checkRedirect(cfg.MaxRedirects, cfg.Destinations),
 	}
 }
 
-// Start lance la boucle de surveillance périodique des URLs.
-// Cette fonction est conçue pour être lancée dans une goroutine séparée.
-func (m *UrlMonitor) Start() {
-	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v...", m.interval)
-	ticker := time.NewTicker(m.interval) // Crée un ticker qui envoie un signal à chaque intervalle
-	defer ticker.Stop()                  // S'assure que le ticker est arrêté quand Start se termine
+// Start lance la boucle de surveillance périodique des URLs jusqu'à l'annulation de 'ctx'.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée ; elle attend la fin
+// de la passe en cours avant de rendre la main.
+func (m *UrlMonitor) Start(ctx context.Context) {
+	m.logger.Info("Démarrage du moniteur d'URLs", "interval", m.cfg.Interval, "concurrency", m.cfg.Concurrency)
+	m.lastBeat.Store(time.Now().UnixNano())
+	m.loadKnownStates(ctx)
+
+	ticker := time.NewTicker(m.cfg.Interval) // Crée un ticker qui envoie un signal à chaque intervalle
+	defer ticker.Stop()                      // S'assure que le ticker est arrêté quand Start se termine
+
+	var passes sync.WaitGroup
+	defer passes.Wait()
 
 	// Exécute une première vérification immédiatement au démarrage
-	m.checkUrls()
+	m.startPass(ctx, &passes)
 
 	// Boucle principale du moniteur, déclenchée par le ticker
-	for range ticker.C {
-		m.checkUrls()
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Arrêt du moniteur d'URLs.")
+			return
+		case <-ticker.C:
+			m.lastBeat.Store(time.Now().UnixNano())
+			m.startPass(ctx, &passes)
+		}
 	}
 }
 
-// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées.
-func (m *UrlMonitor) checkUrls() {
-	log.Println("[MONITOR] Lancement de la vérification de l'état des URLs...")
+// Heartbeat décrit l'activité récente du moniteur.
+type Heartbeat struct {
+	LastBeat    time.Time     // Dernière activité de la boucle (démarrage ou tick), zéro si elle n'a pas démarré
+	LastPassEnd time.Time     // Fin de la dernière passe complète, zéro si aucune
+	PassRunning bool          // Une passe est en cours
+	Interval    time.Duration // Intervalle attendu entre deux ticks
+}
+
+// Heartbeat retourne l'activité récente du moniteur : la boucle est active tant que LastBeat
+// progresse à chaque intervalle, même lorsqu'une passe trop longue fait ignorer des ticks.
+func (m *UrlMonitor) Heartbeat() Heartbeat {
+	return Heartbeat{
+		LastBeat:    unixNano(m.lastBeat.Load()),
+		LastPassEnd: unixNano(m.lastPass.Load()),
+		PassRunning: m.running.Load(),
+		Interval:    m.cfg.Interval,
+	}
+}
+
+// unixNano convertit un horodatage UnixNano, 0 correspondant au temps zéro.
+func unixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// startPass lance une passe de vérification en arrière-plan, sauf si la précédente n'est pas terminée :
+// le tick est alors ignoré plutôt que de superposer deux passes.
+func (m *UrlMonitor) startPass(ctx context.Context, passes *sync.WaitGroup) {
+	if !m.running.CompareAndSwap(false, true) {
+		m.logger.Warn("La vérification précédente est toujours en cours, tick ignoré.")
+		return
+	}
 
-	// TODO : Récupérer toutes les URLs longues actives depuis le linkRepo (GetAllLinks).
-	// Gérer l'erreur si la récupération échoue.
-	// Si erreur : log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
-	links, err := m.linkRepo.GetAllLinks()
+	passes.Add(1)
+	go func() {
+		defer passes.Done()
+		defer m.running.Store(false)
+		m.checkUrls(ctx)
+	}()
+}
+
+// loadKnownStates recharge depuis la base l'état connu de chaque lien, pour ne pas
+// reconsidérer comme « initial » un état déjà observé avant le redémarrage.
+func (m *UrlMonitor) loadKnownStates(ctx context.Context) {
+	states, err := m.healthRepo.GetAllLinkHealth(ctx)
 	if err != nil {
-		log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
+		m.logger.Error("Erreur lors du chargement des états connus", "error", err)
 		return
 	}
+
+	// L'état en base porte toujours sur la destination actuelle (il est supprimé quand elle change).
+	links, err := m.linkRepo.GetActiveLinks(ctx)
+	if err != nil {
+		m.logger.Error("Erreur lors du chargement des destinations surveillées", "error", err)
+		return
+	}
+	destinations := make(map[uint]string, len(links))
 	for _, link := range links {
-		// TODO : Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
-		currentState := m.isUrlAccessible(link.LongURL)
+		destinations[link.ID] = link.LongURL
+	}
 
-		// Protéger l'accès à la map 'knownStates' car 'checkUrls' peut être exécuté concurremment
-		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID] // Récupère l'état précédent
-		m.knownStates[link.ID] = currentState           // Met à jour l'état actuel
-		m.mu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range states {
+		url, ok := destinations[states[i].LinkID]
+		if !ok {
+			continue // Lien expiré ou supprimé : plus surveillé
+		}
+		m.knownStates[states[i].LinkID] = &states[i]
+		m.knownURLs[states[i].LinkID] = url
+	}
+	m.logger.Info("États connus rechargés depuis la base", "count", len(states))
+}
+
+// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées,
+// répartie entre 'Concurrency' workers. La vérification est interrompue dès que 'ctx' est annulé.
+func (m *UrlMonitor) checkUrls(ctx context.Context) {
+	m.logger.Debug("Lancement de la vérification de l'état des URLs...")
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "monitor.pass")
+	defer span.End()
+
+	// Les liens expirés ne sont plus redirigés : inutile de les surveiller.
+	links, err := m.linkRepo.GetActiveLinks(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		m.logger.Error("Erreur lors de la récupération des liens pour la surveillance", "error", err)
+		return
+	}
+	span.SetAttributes(attribute.Int("monitor.links", len(links)))
+	links = m.applyBlocklist(ctx, links)
+
+	jobs := make(chan models.Link)
+	var workers sync.WaitGroup
+	for i := 0; i < m.cfg.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for link := range jobs {
+				if err := m.limiter.Wait(ctx, hostOf(link.LongURL)); err != nil {
+					continue // Arrêt demandé : on vide la file sans contrôler
+				}
+				m.checkLink(ctx, link)
+			}
+		}()
+	}
+
+	// Les liens sont entrelacés par hôte pour que les workers ne restent pas tous
+	// bloqués par la limite de débit d'un même domaine.
+feed:
+	for _, link := range interleaveByHost(links) {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- link:
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	m.limiter.Reset()
+
+	if ctx.Err() != nil {
+		m.logger.Info("Vérification interrompue (arrêt demandé).")
+		return
+	}
 
-		// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
-		if !exists {
-			log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
-				link.ShortCode, link.LongURL, formatState(currentState))
+	if m.cfg.Retention > 0 {
+		if pruned, err := m.healthRepo.PruneHealthChecks(ctx, time.Now().Add(-m.cfg.Retention)); err != nil {
+			m.logger.Error("Erreur lors de la purge de l'historique", "error", err)
+		} else if pruned > 0 {
+			m.logger.Info("Contrôles anciens supprimés de l'historique", "count", pruned, "retention", m.cfg.Retention)
+		}
+	}
+	metrics.MonitorPassDuration.Observe(time.Since(start).Seconds())
+	m.lastPass.Store(time.Now().UnixNano())
+	m.logger.Info("Vérification de l'état des URLs terminée", "links", len(links), "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// checkLink contrôle la destination d'un lien et enregistre le résultat. Chaque contrôle reçoit son identifiant,
+// envoyé à la destination et ajouté à ses logs, et son span, enfant de celui de la passe.
+func (m *UrlMonitor) checkLink(ctx context.Context, link models.Link) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	ctx, span := tracing.Start(ctx, "monitor.check", trace.WithAttributes(
+		attribute.String("link.short_code", link.ShortCode),
+		attribute.String("url.full", link.LongURL),
+	))
+	defer span.End()
+
+	checkStart := time.Now()
+	result := m.checkUrl(ctx, link.LongURL)
+	if ctx.Err() != nil {
+		// Un contrôle interrompu par l'arrêt ne reflète pas l'état de la destination.
+		return
+	}
+	span.SetAttributes(
+		attribute.Bool("monitor.accessible", result.Accessible),
+		attribute.Int("http.response.status_code", result.StatusCode),
+	)
+	metrics.MonitorCheckDuration.Observe(time.Since(checkStart).Seconds())
+	metrics.MonitorChecks.WithLabelValues(strings.ToLower(formatState(result.Accessible))).Inc()
+	m.recordResult(ctx, link, result)
+}
+
+// applyBlocklist bloque les liens dont une destination est désormais interdite, débloque ceux qui ne le sont plus,
+// et retourne les liens non bloqués, seuls à être contrôlés : une destination malveillante n'est jamais contactée.
+// Les règles et la liste de blocage sont chargées une fois par passe puis consultées en mémoire.
+func (m *UrlMonitor) applyBlocklist(ctx context.Context, links []models.Link) []models.Link {
+	snapshot, err := m.cfg.Blocklist.Snapshot(ctx)
+	if err != nil {
+		// Liste de blocage indisponible : l'état de blocage des liens est conservé.
+		m.logger.Error("Erreur lors du chargement de la liste de blocage", "error", err)
+		allowed := links[:0]
+		for _, link := range links {
+			if link.BlockedAt == nil {
+				allowed = append(allowed, link)
+			}
+		}
+		return allowed
+	}
+
+	allowed := links[:0]
+	blocked, unblocked := 0, 0
+	for _, link := range links {
+		if ctx.Err() != nil {
+			break
+		}
+		err := snapshot.Check(ctx, link.LongURL, link.FallbackURL)
+		switch {
+		case errors.Is(err, services.ErrDestinationBlocked):
+			if link.BlockedAt == nil {
+				now := time.Now()
+				if err := m.linkRepo.SetLinkBlocked(ctx, link.ID, &now, textutil.Truncate(err.Error(), 255)); err != nil {
+					m.logger.Error("Erreur lors du blocage du lien", "short_code", link.ShortCode, "error", err)
+					continue
+				}
+				m.logger.Warn("Lien bloqué", "short_code", link.ShortCode, "long_url", link.LongURL, "reason", err)
+				blocked++
+			}
 			continue
+		case err != nil:
+			// Liste de blocage indisponible : l'état de blocage du lien est conservé.
+			m.logger.Error("Erreur lors de la vérification de la liste de blocage", "short_code", link.ShortCode, "error", err)
+			if link.BlockedAt != nil {
+				continue
+			}
+		case link.BlockedAt != nil:
+			if err := m.linkRepo.SetLinkBlocked(ctx, link.ID, nil, ""); err != nil {
+				m.logger.Error("Erreur lors du déblocage du lien", "short_code", link.ShortCode, "error", err)
+				continue
+			}
+			m.logger.Info("Lien débloqué : sa destination n'est plus interdite.", "short_code", link.ShortCode, "long_url", link.LongURL)
+			unblocked++
 		}
+		allowed = append(allowed, link)
+	}
+	if blocked > 0 || unblocked > 0 {
+		m.logger.Info("Liste de blocage appliquée", "blocked", blocked, "unblocked", unblocked)
+	}
+	return allowed
+}
 
-		// TODO : Comparer l'état actuel avec l'état précédent.
-		// Si l'état a changé, générer une fausse notification dans les logs.
-		// log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !"
-		if previousState != currentState {
-			log.Printf(
-				"[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
-				link.ShortCode,
-				link.LongURL,
-				formatState(previousState),
-				formatState(currentState),
-			)
+// interleaveByHost réordonne les liens en alternant les hôtes de destination (un lien par hôte et par tour),
+// en conservant l'ordre d'origine au sein de chaque hôte.
+func interleaveByHost(links []models.Link) []models.Link {
+	var hosts []string
+	byHost := make(map[string][]models.Link)
+	for _, link := range links {
+		host := hostOf(link.LongURL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
 		}
+		byHost[host] = append(byHost[host], link)
+	}
 
+	ordered := make([]models.Link, 0, len(links))
+	for round := 0; len(ordered) < len(links); round++ {
+		for _, host := range hosts {
+			if round < len(byHost[host]) {
+				ordered = append(ordered, byHost[host][round])
+			}
+		}
 	}
-	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
+	return ordered
 }
 
-// isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
-func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	// TODO Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+// recordResult met à jour l'état connu du lien, persiste le contrôle et notifie les changements d'état.
+func (m *UrlMonitor) recordResult(ctx context.Context, link models.Link, result CheckResult) {
+	now := time.Now()
+	check := &models.HealthCheck{
+		LinkID:     link.ID,
+		CheckedAt:  now,
+		Accessible: result.Accessible,
+		StatusCode: result.StatusCode,
+		LatencyMs:  result.Latency.Milliseconds(),
+		Error:      errorString(result.Err),
+		FinalURL:   textutil.Truncate(result.FinalURL, maxURLLength),
+		// Copie : le slice est aussi référencé par l'état courant.
+		RedirectChain: append([]string(nil), result.RedirectChain...),
+		DomainChanged: result.DomainChanged,
+		SoftNotFound:  result.SoftNotFound,
 	}
-	// TODO: Effectuer une requête HEAD (plus légère que GET) sur l'URL.
-	// Un code de statut 2xx ou 3xx indique que l'URL est accessible.
-	// Si err : log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
-	req, err := http.NewRequest(http.MethodHead, url, nil)
-	if err != nil {
-		log.Printf("[MONITOR] Erreur lors de la création de la requête HEAD pour l'URL '%s': %v", url, err)
-		return false
+
+	// Protéger l'accès à la map 'knownStates' car les workers de 'checkUrls' s'exécutent concurremment
+	m.mu.Lock()
+	previous, exists := m.knownStates[link.ID] // Récupère l'état précédent
+	if exists && m.knownURLs[link.ID] != link.LongURL {
+		// Le lien a changé de destination : l'état connu portait sur l'ancienne.
+		previous, exists = nil, false
+	}
+	health := &models.LinkHealth{
+		LinkID:        link.ID,
+		Accessible:    check.Accessible,
+		StatusCode:    check.StatusCode,
+		LatencyMs:     check.LatencyMs,
+		Error:         check.Error,
+		FinalURL:      check.FinalURL,
+		RedirectChain: result.RedirectChain,
+		DomainChanged: check.DomainChanged,
+		SoftNotFound:  check.SoftNotFound,
+		LastCheckedAt: now,
+		LastChangedAt: now,
+	}
+	if exists && previous.Accessible == health.Accessible {
+		health.LastChangedAt = previous.LastChangedAt
 	}
+	if result.Cert != nil {
+		expiresAt := result.Cert.ExpiresAt
+		health.CertExpiresAt = &expiresAt
+		health.CertIssuer = textutil.Truncate(result.Cert.Issuer, 255)
+		health.CertHostnameValid = result.Cert.HostnameValid
+		health.CertError = textutil.Truncate(result.Cert.Err, 255)
+	}
+	if exists && health.FinalURL == "" {
+		// Aucune réponse obtenue : la destination finale est inconnue, on conserve la dernière connue.
+		health.FinalURL = previous.FinalURL
+		health.RedirectChain = previous.RedirectChain
+		health.DomainChanged = previous.DomainChanged
+		if result.Cert == nil {
+			health.CertExpiresAt = previous.CertExpiresAt
+			health.CertIssuer = previous.CertIssuer
+			health.CertHostnameValid = previous.CertHostnameValid
+			health.CertError = previous.CertError
+		}
+	}
+	if !health.Accessible {
+		health.ConsecutiveFailures = 1
+		if exists {
+			health.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		}
+	}
+	m.knownStates[link.ID] = health // Met à jour l'état actuel
+	m.knownURLs[link.ID] = link.LongURL
+	m.mu.Unlock()
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
+	if err := m.healthRepo.SaveCheck(ctx, check, health, link.LongURL); errors.Is(err, repository.ErrDestinationChanged) {
+		// La destination a changé pendant le contrôle : le résultat est ignoré, la nouvelle sera contrôlée à la prochaine passe.
+		m.mu.Lock()
+		if m.knownURLs[link.ID] == link.LongURL {
+			delete(m.knownStates, link.ID)
+			delete(m.knownURLs, link.ID)
+		}
+		m.mu.Unlock()
+		m.logger.InfoContext(ctx, "Destination modifiée pendant le contrôle, résultat ignoré", "short_code", link.ShortCode)
+		return
+	} else if err != nil {
+		m.logger.ErrorContext(ctx, "Erreur lors de l'enregistrement du contrôle", "short_code", link.ShortCode, "error", err)
+	}
+
+	// Un certificat proche de l'expiration est signalé dès le premier contrôle, puis une seule fois par certificat.
+	if m.certExpiring(health) && !(exists && m.certExpiring(previous) && previous.CertExpiresAt.Equal(*health.CertExpiresAt)) {
+		m.logger.WarnContext(ctx, "Certificat TLS proche de l'expiration",
+			"short_code", link.ShortCode, "long_url", link.LongURL, "expires_at", health.CertExpiresAt.Format(time.DateOnly))
+		m.dispatcher.Dispatch(notifier.Event{
+			Type:          notifier.EventCertExpiring,
+			LinkID:        link.ID,
+			ShortCode:     link.ShortCode,
+			LongURL:       link.LongURL,
+			CurrentState:  health.CertExpiresAt.Format(time.DateOnly),
+			FinalURL:      health.FinalURL,
+			CertExpiresAt: health.CertExpiresAt,
+			CertIssuer:    health.CertIssuer,
+			OccurredAt:    now,
+		})
+	}
+
+	// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier.
+	if !exists {
+		m.logger.InfoContext(ctx, "État initial du lien",
+			"short_code", link.ShortCode, "long_url", link.LongURL, "state", formatState(health.Accessible))
+		return
+	}
+
+	if previous.Accessible != health.Accessible {
+		m.logger.WarnContext(ctx, "Changement d'état du lien",
+			"short_code", link.ShortCode,
+			"long_url", link.LongURL,
+			"previous_state", formatState(previous.Accessible),
+			"state", formatState(health.Accessible),
+		)
+		m.dispatcher.Dispatch(notifier.Event{
+			Type:          notifier.EventStateChange,
+			LinkID:        link.ID,
+			ShortCode:     link.ShortCode,
+			LongURL:       link.LongURL,
+			PreviousState: formatState(previous.Accessible),
+			CurrentState:  formatState(health.Accessible),
+			StatusCode:    health.StatusCode,
+			Error:         health.Error,
+			OccurredAt:    now,
+		})
+	}
+
+	if health.DomainChanged && !previous.DomainChanged {
+		m.logger.WarnContext(ctx, "Le lien redirige désormais vers un autre domaine",
+			"short_code", link.ShortCode, "long_url", link.LongURL, "final_url", health.FinalURL)
+		m.dispatcher.Dispatch(notifier.Event{
+			Type:          notifier.EventDomainChange,
+			LinkID:        link.ID,
+			ShortCode:     link.ShortCode,
+			LongURL:       link.LongURL,
+			PreviousState: registrableDomain(link.LongURL),
+			CurrentState:  registrableDomain(health.FinalURL),
+			FinalURL:      health.FinalURL,
+			StatusCode:    health.StatusCode,
+			OccurredAt:    now,
+		})
+	}
+}
+
+// certExpiring indique si le certificat relevé lors du contrôle expirait dans la fenêtre d'alerte.
+func (m *UrlMonitor) certExpiring(health *models.LinkHealth) bool {
+	if m.cfg.CertExpiryWarning <= 0 || health.CertExpiresAt == nil {
 		return false
 	}
-	// TODO Assurez-vous de fermer le corps de la réponse pour libérer les ressources
-	defer resp.Body.Close()
-	
-	// Déterminer l'accessibilité basée sur le code de statut HTTP.
-	return resp.StatusCode >= 200 && resp.StatusCode < 400 // Codes 2xx ou 3xx
+	return health.CertExpiresAt.Sub(health.LastCheckedAt) < m.cfg.CertExpiryWarning
+}
+
+// errorString tronque le message d'erreur pour le stockage (vide si err est nil).
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return textutil.Truncate(err.Error(), maxErrorLength)
 }
 
 // formatState est une fonction utilitaire pour rendre l'état plus lisible dans les logs.
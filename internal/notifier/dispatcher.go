@@ -0,0 +1,255 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/urlguard"
+)
+
+// Canaux disponibles pour les abonnements par lien.
+const (
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelEmail   = "email"
+)
+
+// DispatcherConfig règle la livraison des notifications.
+type DispatcherConfig struct {
+	QueueSize    int
+	Timeout      time.Duration // Délai maximal d'une tentative de livraison
+	MaxAttempts  int
+	RetryBackoff time.Duration // Délai avant la 2e tentative, doublé à chaque nouvel échec
+	// DedupWindow est la durée minimale entre deux notifications d'un même lien.
+	// Les événements reçus entre-temps sont regroupés : seul le dernier état est notifié
+	// à la fin de la fenêtre, et rien n'est envoyé si le lien est revenu à l'état déjà notifié.
+	DedupWindow time.Duration
+}
+
+// dedupKey identifie un flux d'événements à dédupliquer.
+type dedupKey struct {
+	linkID    uint
+	eventType string
+}
+
+// sentState mémorise la dernière notification envoyée pour un flux.
+type sentState struct {
+	state string
+	at    time.Time
+}
+
+// Dispatcher livre les événements du moniteur aux canaux globaux (configuration)
+// et aux abonnements du lien concerné, de façon asynchrone.
+type Dispatcher struct {
+	global  []Notifier
+	subRepo repository.SubscriptionRepository
+	smtp    SMTPConfig
+	client  *http.Client
+	cfg     DispatcherConfig
+	queue   chan Event
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	lastSent map[dedupKey]sentState
+	pending  map[dedupKey]Event
+}
+
+// NewDispatcher crée un Dispatcher. 'global' reçoit tous les événements ;
+// la configuration SMTP sert aussi aux abonnements e-mail. Les webhooks des abonnements, fournis par
+// les utilisateurs, ne peuvent joindre que les destinations autorisées par 'destinations'.
+func NewDispatcher(global []Notifier, subRepo repository.SubscriptionRepository, smtp SMTPConfig, destinations *urlguard.Policy, cfg DispatcherConfig) *Dispatcher {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Dispatcher{
+		global:   global,
+		subRepo:  subRepo,
+		smtp:     smtp,
+		client:   newSubscriptionClient(destinations, cfg.Timeout),
+		cfg:      cfg,
+		queue:    make(chan Event, cfg.QueueSize),
+		logger:   slog.Default().With("component", "notifier"),
+		lastSent: make(map[dedupKey]sentState),
+		pending:  make(map[dedupKey]Event),
+	}
+}
+
+// newSubscriptionClient construit le client HTTP des webhooks d'abonnement : chaque connexion
+// et chaque redirection est vérifiée par la politique des destinations.
+func newSubscriptionClient(destinations *urlguard.Policy, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: destinations.Control}
+	return &http.Client{
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			_, err := destinations.CheckStatic(req.URL.String())
+			return err
+		},
+	}
+}
+
+// Dispatch met un événement en file d'attente sans bloquer l'appelant.
+func (d *Dispatcher) Dispatch(event Event) {
+	key := dedupKey{linkID: event.LinkID, eventType: event.Type}
+
+	d.mu.Lock()
+	last, seen := d.lastSent[key]
+	if seen && d.cfg.DedupWindow > 0 && event.OccurredAt.Sub(last.at) < d.cfg.DedupWindow {
+		// Lien instable : on retient le dernier état, envoyé (ou non) à la fin de la fenêtre.
+		d.pending[key] = event
+		d.mu.Unlock()
+		d.logger.Debug("Notification différée (fenêtre de déduplication).", "short_code", event.ShortCode)
+		return
+	}
+	d.lastSent[key] = sentState{state: event.CurrentState, at: event.OccurredAt}
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	d.enqueue(event)
+}
+
+func (d *Dispatcher) enqueue(event Event) {
+	select {
+	case d.queue <- event:
+	default:
+		d.logger.Warn("File de notifications pleine, notification abandonnée.", "short_code", event.ShortCode)
+	}
+}
+
+// Start livre les notifications jusqu'à l'annulation de 'ctx'.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.logger.Info("Démarrage du service de notifications...")
+
+	var flush <-chan time.Time
+	if d.cfg.DedupWindow > 0 {
+		ticker := time.NewTicker(min(d.cfg.DedupWindow, 30*time.Second))
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if pending := len(d.queue); pending > 0 {
+				d.logger.Warn("Notifications non envoyées à l'arrêt", "count", pending)
+			}
+			d.logger.Info("Arrêt du service de notifications.")
+			return
+		case event := <-d.queue:
+			d.deliver(ctx, event)
+		case now := <-flush:
+			for _, event := range d.duePending(now) {
+				d.deliver(ctx, event)
+			}
+		}
+	}
+}
+
+// duePending retourne les événements différés dont la fenêtre de déduplication est écoulée
+// et qui annoncent un état différent du dernier notifié.
+func (d *Dispatcher) duePending(now time.Time) []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var due []Event
+	for key, event := range d.pending {
+		last := d.lastSent[key]
+		if now.Sub(last.at) < d.cfg.DedupWindow {
+			continue
+		}
+		delete(d.pending, key)
+		if event.CurrentState == last.state {
+			d.logger.Info("Lien revenu à son état notifié : notification ignorée.", "short_code", event.ShortCode, "state", last.state)
+			continue
+		}
+		event.PreviousState = last.state
+		d.lastSent[key] = sentState{state: event.CurrentState, at: now}
+		due = append(due, event)
+	}
+	return due
+}
+
+// deliver envoie l'événement à chaque canal concerné, avec nouvelles tentatives.
+func (d *Dispatcher) deliver(ctx context.Context, event Event) {
+	notifiers := append([]Notifier{}, d.global...)
+	subs, err := d.subRepo.ListSubscriptionsByLink(ctx, event.LinkID)
+	if err != nil {
+		d.logger.Error("Erreur lors de la récupération des abonnements", "short_code", event.ShortCode, "error", err)
+	}
+	for _, sub := range subs {
+		if n := d.subscriptionNotifier(sub); n != nil {
+			notifiers = append(notifiers, n)
+		}
+	}
+
+	for _, n := range notifiers {
+		if err := d.sendWithRetry(ctx, n, event); err != nil {
+			d.logger.Error("Notification abandonnée", "short_code", event.ShortCode, "channel", n.Name(), "error", err)
+		}
+	}
+}
+
+// subscriptionNotifier construit le canal correspondant à un abonnement (nil si le canal n'est pas disponible).
+func (d *Dispatcher) subscriptionNotifier(sub models.LinkSubscription) Notifier {
+	switch sub.Channel {
+	case ChannelWebhook:
+		return &WebhookNotifier{URL: sub.Target, Secret: sub.Secret, Format: FormatJSON, Label: subscriptionLabel(sub), Client: d.client}
+	case ChannelSlack:
+		return &WebhookNotifier{URL: sub.Target, Format: FormatSlack, Label: subscriptionLabel(sub), Client: d.client}
+	case ChannelEmail:
+		if !d.smtp.Enabled() {
+			d.logger.Warn("Abonnement e-mail ignoré : SMTP non configuré.", "subscription_id", sub.ID)
+			return nil
+		}
+		return &SMTPNotifier{Config: d.smtp, To: []string{sub.Target}}
+	default:
+		d.logger.Warn("Abonnement ignoré : canal inconnu.", "subscription_id", sub.ID, "channel", sub.Channel)
+		return nil
+	}
+}
+
+// subscriptionLabel identifie un abonnement dans les logs sans révéler sa cible.
+func subscriptionLabel(sub models.LinkSubscription) string {
+	return fmt.Sprintf("subscription %d", sub.ID)
+}
+
+func (d *Dispatcher) sendWithRetry(ctx context.Context, n Notifier, event Event) error {
+	backoff := d.cfg.RetryBackoff
+	var err error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+		err = n.Notify(attemptCtx, event)
+		cancel()
+		if err == nil {
+			d.logger.Info("Lien notifié", "short_code", event.ShortCode, "channel", n.Name())
+			return nil
+		}
+		if attempt == d.cfg.MaxAttempts {
+			break
+		}
+		d.logger.Warn("Échec de la tentative de notification", "attempt", attempt, "max_attempts", d.cfg.MaxAttempts, "channel", n.Name(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Types d'événements émis par le moniteur.
+const (
+	EventStateChange  = "state_change"
+	EventDomainChange = "domain_change"
+	EventCertExpiring = "cert_expiring"
+)
+
+// Event décrit un événement du moniteur à notifier.
+type Event struct {
+	Type          string     `json:"type"`
+	LinkID        uint       `json:"link_id"`
+	ShortCode     string     `json:"short_code"`
+	LongURL       string     `json:"long_url"`
+	PreviousState string     `json:"previous_state,omitempty"`
+	CurrentState  string     `json:"current_state,omitempty"`
+	FinalURL      string     `json:"final_url,omitempty"`
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty"`
+	CertIssuer    string     `json:"cert_issuer,omitempty"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+}
+
+// Subject retourne un titre court décrivant l'événement (objet d'e-mail, titre Slack).
+func (e Event) Subject() string {
+	switch e.Type {
+	case EventDomainChange:
+		return fmt.Sprintf("[url-shortener] %s : changement de domaine (%s)", e.ShortCode, e.CurrentState)
+	case EventCertExpiring:
+		return fmt.Sprintf("[url-shortener] %s : certificat TLS expirant le %s", e.ShortCode, e.CurrentState)
+	}
+	return fmt.Sprintf("[url-shortener] %s : %s", e.ShortCode, e.CurrentState)
+}
+
+// Text retourne une description lisible de l'événement.
+func (e Event) Text() string {
+	var text string
+	switch e.Type {
+	case EventDomainChange:
+		text = fmt.Sprintf("Le lien %s (%s) redirige désormais vers un autre domaine : %s (auparavant %s).",
+			e.ShortCode, e.LongURL, e.FinalURL, e.PreviousState)
+	case EventCertExpiring:
+		verb := "expire"
+		if e.CertExpiresAt != nil && e.CertExpiresAt.Before(e.OccurredAt) {
+			verb = "a expiré"
+		}
+		text = fmt.Sprintf("Le certificat TLS de la destination du lien %s (%s) %s le %s (émetteur : %s).",
+			e.ShortCode, e.LongURL, verb, e.CurrentState, e.CertIssuer)
+	default:
+		text = fmt.Sprintf("Le lien %s (%s) est passé de %s à %s !", e.ShortCode, e.LongURL, e.PreviousState, e.CurrentState)
+	}
+	if e.StatusCode != 0 {
+		text += fmt.Sprintf("\nCode HTTP : %d", e.StatusCode)
+	}
+	if e.Error != "" {
+		text += "\nErreur : " + e.Error
+	}
+	return text
+}
+
+// Notifier est un canal de notification (webhook, e-mail, Slack, ...).
+type Notifier interface {
+	// Name identifie le canal dans les logs.
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig contient les paramètres du serveur d'envoi des e-mails.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled indique si un serveur SMTP est configuré.
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// SMTPNotifier envoie les événements par e-mail.
+type SMTPNotifier struct {
+	Config SMTPConfig
+	To     []string
+}
+
+func (n *SMTPNotifier) Name() string {
+	return "email " + strings.Join(n.To, ",")
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, event Event) error {
+	addr := net.JoinHostPort(n.Config.Host, strconv.Itoa(n.Config.Port))
+
+	var auth smtp.Auth
+	if n.Config.Username != "" {
+		auth = smtp.PlainAuth("", n.Config.Username, n.Config.Password, n.Config.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + n.Config.From,
+		"To: " + strings.Join(n.To, ", "),
+		"Subject: " + event.Subject(),
+		"Date: " + event.OccurredAt.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		event.Text(),
+	}, "\r\n")
+
+	// net/smtp ne gère pas les contextes : l'envoi est fait dans une goroutine pour respecter l'annulation.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.Config.From, n.To, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("email delivery failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
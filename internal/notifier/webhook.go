@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Formats de charge utile supportés par WebhookNotifier.
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// En-têtes ajoutés aux webhooks signés. La signature est un HMAC-SHA256 (hexadécimal) de
+// "<timestamp>.<corps>" avec le secret partagé ; le destinataire doit vérifier les deux en-têtes.
+const (
+	SignatureHeader = "X-Urlshortener-Signature"
+	TimestampHeader = "X-Urlshortener-Timestamp"
+)
+
+// WebhookNotifier envoie les événements en POST à une URL, au format JSON brut
+// ou au format des webhooks entrants Slack ({"text": ...}).
+// L'URL porte souvent un secret (webhooks Slack) : elle n'apparaît ni dans Name ni dans les erreurs.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Format string
+	Label  string       // Identifie le webhook dans les logs (ex: "subscription 12") ; à défaut, l'hôte de l'URL
+	Client *http.Client // http.DefaultClient si nil ; le délai est borné par le contexte de Notify
+}
+
+func (n *WebhookNotifier) Name() string {
+	if n.Label != "" {
+		return "webhook " + n.Label
+	}
+	if u, err := url.Parse(n.URL); err == nil && u.Host != "" {
+		return "webhook " + u.Host
+	}
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	var payload any = event
+	if n.Format == FormatSlack {
+		payload = map[string]string{"text": "*" + event.Subject() + "*\n" + event.Text()}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("failed to build webhook request: invalid URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.Secret, timestamp, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// *url.Error reprend l'URL complète : seule la cause est conservée.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign calcule la signature HMAC-SHA256 (hexadécimale) d'un webhook.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const secretPath = "/services/T000/B000/s3cr3t"
+
+func TestWebhookNotifierName(t *testing.T) {
+	tests := []struct {
+		notifier WebhookNotifier
+		want     string
+	}{
+		{WebhookNotifier{URL: "https://hooks.slack.com" + secretPath}, "webhook hooks.slack.com"},
+		{WebhookNotifier{URL: "https://hooks.example:8443/hook?token=s3cr3t"}, "webhook hooks.example:8443"},
+		{WebhookNotifier{URL: "https://hooks.slack.com" + secretPath, Label: "subscription 12"}, "webhook subscription 12"},
+		{WebhookNotifier{URL: "not a url"}, "webhook"},
+	}
+	for _, tt := range tests {
+		if got := tt.notifier.Name(); got != tt.want {
+			t.Errorf("Name() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestWebhookNotifierErrorsHideURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	rejecting := server.URL + secretPath
+	closed := httptest.NewServer(http.NotFoundHandler())
+	unreachable := closed.URL + secretPath
+	closed.Close()
+	defer server.Close()
+
+	for _, target := range []string{rejecting, unreachable, "http://[::1" + secretPath} {
+		n := &WebhookNotifier{URL: target, Format: FormatJSON}
+		err := n.Notify(context.Background(), Event{Type: EventStateChange, ShortCode: "abc"})
+		if err == nil {
+			t.Fatalf("Notify(%s) succeeded, want an error", target)
+		}
+		if strings.Contains(err.Error(), "s3cr3t") {
+			t.Errorf("Notify error %q reveals the webhook URL", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package readiness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/monitor"
+)
+
+// Database vérifie que la base répond (et, pour SQLite, que son fichier existe toujours),
+// et ajoute l'état du pool de connexions au rapport.
+func Database(sqlDB *sql.DB, cfg config.DatabaseConfig) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		err := database.Ping(ctx, sqlDB, cfg)
+		stats := sqlDB.Stats()
+		return map[string]any{
+			"driver":           database.Describe(cfg),
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+			"wait_count":       stats.WaitCount,
+		}, err
+	}
+}
+
+// Migrations vérifie que toutes les migrations connues de ce binaire sont appliquées.
+// Une migration appliquée mais inconnue (base migrée par une version plus récente) est signalée sans échec.
+func Migrations(migrator *migrations.Migrator) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		statuses, err := migrator.WithContext(ctx).Status()
+		if err != nil {
+			return nil, err
+		}
+
+		version, unknown := 0, 0
+		var pending []string
+		for _, status := range statuses {
+			switch {
+			case status.AppliedAt == nil:
+				pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+			case status.Unknown:
+				unknown++
+				version = status.Version
+			default:
+				version = status.Version
+			}
+		}
+		details := map[string]any{"version": version, "pending": len(pending), "unknown": unknown}
+		if len(pending) > 0 {
+			return details, fmt.Errorf("%d pending migration(s), first %s", len(pending), pending[0])
+		}
+		return details, nil
+	}
+}
+
+// ClickChannel vérifie que le channel des événements de clic n'est pas saturé : au-delà de 'maxFill'
+// (fraction de sa capacité), les workers ne suivent plus et les clics partent dans le journal.
+func ClickChannel(clickChan chan models.ClickEvent, maxFill float64) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		depth, capacity := len(clickChan), cap(clickChan)
+		details := map[string]any{"depth": depth, "capacity": capacity}
+		if capacity == 0 {
+			return details, nil
+		}
+		fill := float64(depth) / float64(capacity)
+		details["fill"] = fill
+		if fill >= maxFill {
+			return details, fmt.Errorf("click channel saturated (%d/%d events)", depth, capacity)
+		}
+		return details, nil
+	}
+}
+
+// Monitor vérifie que la boucle du moniteur d'URLs est active : elle doit avoir démarré et s'être
+// manifestée depuis moins de 'staleIntervals' intervalles (0 = seul le démarrage est vérifié).
+func Monitor(urlMonitor *monitor.UrlMonitor, staleIntervals int) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		heartbeat := urlMonitor.Heartbeat()
+		details := map[string]any{
+			"pass_running":     heartbeat.PassRunning,
+			"interval_seconds": heartbeat.Interval.Seconds(),
+		}
+		if !heartbeat.LastPassEnd.IsZero() {
+			details["last_pass_completed_at"] = heartbeat.LastPassEnd.UTC()
+		}
+		if heartbeat.LastBeat.IsZero() {
+			return details, errors.New("monitor not started")
+		}
+		age := time.Since(heartbeat.LastBeat)
+		details["last_heartbeat_at"] = heartbeat.LastBeat.UTC()
+		details["heartbeat_age_seconds"] = age.Seconds()
+		if limit := heartbeat.Interval * time.Duration(staleIntervals); staleIntervals > 0 && age > limit {
+			return details, fmt.Errorf("monitor inactive for %s (limit %s)", age.Round(time.Second), limit)
+		}
+		return details, nil
+	}
+}
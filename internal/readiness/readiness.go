@@ -0,0 +1,147 @@
+package readiness
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// États d'une vérification et du rapport.
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"
+)
+
+// Check vérifie une dépendance du service et retourne des détails à inclure dans le rapport.
+// Une erreur rend le service indisponible ; les détails sont conservés dans le rapport.
+type Check func(ctx context.Context) (map[string]any, error)
+
+// CheckResult est le résultat d'une vérification.
+type CheckResult struct {
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// Report est le rapport de disponibilité retourné par /readyz.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready indique si toutes les vérifications ont réussi.
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker exécute les vérifications de disponibilité enregistrées, en parallèle et dans un délai borné.
+type Checker struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	degraded atomic.Bool  // État du dernier rapport, pour ne journaliser que les changements
+	logger   *slog.Logger // Logger portant l'attribut component=readiness
+}
+
+// NewChecker crée un Checker dont chaque exécution est limitée à 'timeout'.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{
+		timeout: timeout,
+		logger:  slog.Default().With("component", "readiness"),
+	}
+}
+
+// Add enregistre une vérification sous le nom utilisé dans le rapport.
+// À appeler avant la première exécution.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run exécute toutes les vérifications. Une vérification qui n'a pas répondu dans le délai est en échec,
+// sans attendre sa fin : une base bloquée ne doit pas bloquer la sonde.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type namedResult struct {
+		name   string
+		result CheckResult
+	}
+	results := make(chan namedResult, len(c.checks))
+	for _, check := range c.checks {
+		go func() {
+			results <- namedResult{name: check.name, result: run(ctx, check.check)}
+		}()
+	}
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	for _, check := range c.checks {
+		report.Checks[check.name] = CheckResult{
+			Status:     StatusFail,
+			Error:      "check timed out",
+			DurationMs: c.timeout.Milliseconds(),
+		}
+	}
+collect:
+	for range c.checks {
+		select {
+		case r := <-results:
+			report.Checks[r.name] = r.result
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	var failed []string
+	for name, result := range report.Checks {
+		if result.Status != StatusOK {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		report.Status = StatusDegraded
+	}
+	sort.Strings(failed)
+	c.logTransition(failed)
+	return report
+}
+
+// run exécute une vérification et mesure sa durée.
+func run(ctx context.Context, check Check) CheckResult {
+	start := time.Now()
+	details, err := check(ctx)
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+		Details:    details,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// logTransition journalise le passage à l'état indisponible, ou le retour à l'état disponible.
+func (c *Checker) logTransition(failed []string) {
+	degraded := len(failed) > 0
+	if c.degraded.Swap(degraded) == degraded {
+		return
+	}
+	if degraded {
+		c.logger.Warn("Service indisponible", "failed_checks", failed)
+	} else {
+		c.logger.Info("Service de nouveau disponible")
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// AbuseReportFilter restreint la liste des signalements (champs vides = pas de filtre).
+type AbuseReportFilter struct {
+	Status string
+	LinkID *uint
+	Limit  int
+}
+
+type AbuseRepository interface {
+	CreateReport(ctx context.Context, report *models.AbuseReport) error
+	GetReport(ctx context.Context, id uint) (*models.AbuseReport, error)
+	ListReports(ctx context.Context, filter AbuseReportFilter) ([]models.AbuseReport, error)
+	HasOpenReport(ctx context.Context, linkID uint, reporterIP string) (bool, error)
+	ResolveReport(ctx context.Context, id uint, status, resolution string, resolvedAt time.Time) error
+	ResolveLinkReports(ctx context.Context, linkID uint, status, resolution string, resolvedAt time.Time) (int64, error)
+}
+
+type GormAbuseRepository struct {
+	db *gorm.DB
+}
+
+func NewAbuseRepository(db *gorm.DB) *GormAbuseRepository {
+	return &GormAbuseRepository{db: db}
+}
+
+func (r *GormAbuseRepository) CreateReport(ctx context.Context, report *models.AbuseReport) error {
+	result := r.db.WithContext(ctx).Omit("Link").Create(report)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create abuse report: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *GormAbuseRepository) GetReport(ctx context.Context, id uint) (*models.AbuseReport, error) {
+	var report models.AbuseReport
+	result := r.db.WithContext(ctx).Preload("Link", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&report, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// ListReports retourne les signalements, du plus récent au plus ancien, avec leur lien (même supprimé).
+func (r *GormAbuseRepository) ListReports(ctx context.Context, filter AbuseReportFilter) ([]models.AbuseReport, error) {
+	query := r.db.WithContext(ctx).Preload("Link", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.LinkID != nil {
+		query = query.Where("link_id = ?", *filter.LinkID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var reports []models.AbuseReport
+	result := query.Order("created_at DESC").Order("id DESC").Find(&reports)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list abuse reports: %w", result.Error)
+	}
+	return reports, nil
+}
+
+// HasOpenReport indique si 'reporterIP' a déjà un signalement en attente pour ce lien.
+func (r *GormAbuseRepository) HasOpenReport(ctx context.Context, linkID uint, reporterIP string) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.AbuseReport{}).
+		Where("link_id = ? AND reporter_ip = ? AND status = ?", linkID, reporterIP, models.ReportOpen).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to count abuse reports: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// ResolveReport clôt un signalement en attente. Retourne gorm.ErrRecordNotFound s'il n'existe pas ou est déjà traité.
+func (r *GormAbuseRepository) ResolveReport(ctx context.Context, id uint, status, resolution string, resolvedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.AbuseReport{}).
+		Where("id = ? AND status = ?", id, models.ReportOpen).
+		Updates(map[string]interface{}{"status": status, "resolution": resolution, "resolved_at": resolvedAt})
+	if result.Error != nil {
+		return fmt.Errorf("failed to resolve abuse report %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResolveLinkReports clôt tous les signalements en attente d'un lien et retourne leur nombre.
+func (r *GormAbuseRepository) ResolveLinkReports(ctx context.Context, linkID uint, status, resolution string, resolvedAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.AbuseReport{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportOpen).
+		Updates(map[string]interface{}{"status": status, "resolution": resolution, "resolved_at": resolvedAt})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to resolve abuse reports of link %d: %w", linkID, result.Error)
+	}
+	return result.RowsAffected, nil
+}
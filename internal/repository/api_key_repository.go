@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+func (r *GormAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	result := r.db.WithContext(ctx).Create(key)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create API key: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *GormAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *GormAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.WithContext(ctx).First(&key, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *GormAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	result := r.db.WithContext(ctx).Order("id").Find(&keys)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", result.Error)
+	}
+	return keys, nil
+}
+
+func (r *GormAPIKeyRepository) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key %d: %w", id, result.Error)
+	}
+	return nil
+}
+
+// TouchAPIKey met à jour la date de dernière utilisation d'une clé.
+func (r *GormAPIKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update API key %d usage: %w", id, result.Error)
+	}
+	return nil
+}
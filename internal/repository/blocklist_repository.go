@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDuplicateDomainRule est retournée par CreateDomainRule lorsqu'une règle existe déjà pour ce domaine.
+var ErrDuplicateDomainRule = errors.New("a rule already exists for this domain")
+
+// BlocklistSource résume les entrées importées depuis un même fichier.
+type BlocklistSource struct {
+	Source  string
+	Entries int64
+}
+
+type BlocklistRepository interface {
+	CreateDomainRule(ctx context.Context, rule *models.DomainRule) error
+	ListDomainRules(ctx context.Context) ([]models.DomainRule, error)
+	DeleteDomainRule(ctx context.Context, domain string) error
+	FindDomainRules(ctx context.Context, domains []string) ([]models.DomainRule, error)
+	HasAllowRules(ctx context.Context) (bool, error)
+	ImportEntries(ctx context.Context, source string, entries []models.BlocklistEntry, replace bool) (int64, error)
+	ListSources(ctx context.Context) ([]BlocklistSource, error)
+	ListEntries(ctx context.Context) ([]models.BlocklistEntry, error)
+	MatchEntry(ctx context.Context, hosts, hashes, prefixes []string) (*models.BlocklistEntry, error)
+}
+
+type GormBlocklistRepository struct {
+	db *gorm.DB
+}
+
+func NewBlocklistRepository(db *gorm.DB) *GormBlocklistRepository {
+	return &GormBlocklistRepository{db: db}
+}
+
+func (r *GormBlocklistRepository) CreateDomainRule(ctx context.Context, rule *models.DomainRule) error {
+	result := r.db.WithContext(ctx).Create(rule)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateDomainRule
+	}
+	if result.Error != nil {
+		return fmt.Errorf("failed to create domain rule: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *GormBlocklistRepository) ListDomainRules(ctx context.Context) ([]models.DomainRule, error) {
+	var rules []models.DomainRule
+	result := r.db.WithContext(ctx).Order("action").Order("domain").Find(&rules)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list domain rules: %w", result.Error)
+	}
+	return rules, nil
+}
+
+// DeleteDomainRule supprime la règle du domaine. Retourne gorm.ErrRecordNotFound si elle n'existe pas.
+func (r *GormBlocklistRepository) DeleteDomainRule(ctx context.Context, domain string) error {
+	result := r.db.WithContext(ctx).Where("domain = ?", domain).Delete(&models.DomainRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete domain rule %q: %w", domain, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDomainRules retourne les règles portant sur l'un des domaines donnés.
+func (r *GormBlocklistRepository) FindDomainRules(ctx context.Context, domains []string) ([]models.DomainRule, error) {
+	var rules []models.DomainRule
+	result := r.db.WithContext(ctx).Where("domain IN ?", domains).Find(&rules)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve domain rules: %w", result.Error)
+	}
+	return rules, nil
+}
+
+// HasAllowRules indique si au moins une règle "allow" existe (la liste d'autorisation est alors active).
+func (r *GormBlocklistRepository) HasAllowRules(ctx context.Context) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.DomainRule{}).Where("action = ?", models.DomainRuleAllow).Limit(1).Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to count allow rules: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// ImportEntries ajoute les entrées d'une source en une transaction ; les doublons sont ignorés.
+// Avec 'replace', les entrées précédemment importées depuis cette source sont d'abord supprimées.
+// Retourne le nombre d'entrées réellement ajoutées.
+func (r *GormBlocklistRepository) ImportEntries(ctx context.Context, source string, entries []models.BlocklistEntry, replace bool) (int64, error) {
+	var added int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if result := tx.Where("source = ?", source).Delete(&models.BlocklistEntry{}); result.Error != nil {
+				return result.Error
+			}
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 500)
+		added = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import blocklist %q: %w", source, err)
+	}
+	return added, nil
+}
+
+func (r *GormBlocklistRepository) ListSources(ctx context.Context) ([]BlocklistSource, error) {
+	var sources []BlocklistSource
+	result := r.db.WithContext(ctx).Model(&models.BlocklistEntry{}).
+		Select("source, COUNT(*) AS entries").
+		Group("source").Order("source").
+		Scan(&sources)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list blocklist sources: %w", result.Error)
+	}
+	return sources, nil
+}
+
+// ListEntries retourne toutes les entrées de la liste de blocage, par ordre d'import.
+func (r *GormBlocklistRepository) ListEntries(ctx context.Context) ([]models.BlocklistEntry, error) {
+	var entries []models.BlocklistEntry
+	result := r.db.WithContext(ctx).Order("id").Find(&entries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list blocklist entries: %w", result.Error)
+	}
+	return entries, nil
+}
+
+// MatchEntry retourne la première entrée correspondant à l'un des noms d'hôte, à l'une des empreintes
+// ou à l'un des préfixes d'URL. Chaque critère est une égalité exacte sur (kind, value), servie par l'index
+// de la table. Retourne nil si aucune entrée ne correspond.
+func (r *GormBlocklistRepository) MatchEntry(ctx context.Context, hosts, hashes, prefixes []string) (*models.BlocklistEntry, error) {
+	var entries []models.BlocklistEntry
+	result := r.db.WithContext(ctx).
+		Where("kind = ? AND value IN ?", models.BlocklistHost, hosts).
+		Or("kind = ? AND value IN ?", models.BlocklistHash, hashes).
+		Or("kind = ? AND value IN ?", models.BlocklistPrefix, prefixes).
+		Order("id").Limit(1).
+		Find(&entries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to match blocklist entries: %w", result.Error)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/cache"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// LinkCacheConfig règle le cache des liens.
+type LinkCacheConfig struct {
+	Size        int           // Nombre maximal de codes courts en cache
+	TTL         time.Duration // Durée de vie d'un lien en cache
+	NegativeTTL time.Duration // Durée de vie d'un code inconnu en cache (0 = pas de cache négatif)
+}
+
+// LinkCacheStats sont les compteurs du cache des liens.
+type LinkCacheStats struct {
+	cache.Stats
+	NegativeHits uint64 // Lectures servies par le cache négatif (codes inconnus)
+}
+
+// CachedLinkRepository ajoute un cache LRU en mémoire devant GetLinkByShortCode, la lecture du chemin
+// de redirection. Les codes inconnus sont aussi mis en cache (cache négatif), pour une durée plus courte.
+// Les écritures passant par ce repository invalident les entrées concernées ; celles faites par un autre
+// processus (commandes CLI, autres instances) sont lues dans la table des modifications par WatchChanges.
+type CachedLinkRepository struct {
+	LinkRepository
+	cfg          LinkCacheConfig
+	links        *cache.LRU[string, *models.Link] // nil = code inconnu (cache négatif)
+	negativeHits atomic.Uint64
+}
+
+func NewCachedLinkRepository(next LinkRepository, cfg LinkCacheConfig) *CachedLinkRepository {
+	return &CachedLinkRepository{
+		LinkRepository: next,
+		cfg:            cfg,
+		links:          cache.NewLRU[string, *models.Link](cfg.Size),
+	}
+}
+
+// GetLinkByShortCode retourne une copie du lien en cache, ou le lit en base et le met en cache.
+func (r *CachedLinkRepository) GetLinkByShortCode(ctx context.Context, shortCode string) (*models.Link, error) {
+	link, ok := r.links.Get(shortCode)
+	// Le résultat est ajouté au span de l'appelant : une lecture en cache n'émet pas de requête GORM.
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		if link == nil {
+			r.negativeHits.Add(1)
+			return nil, gorm.ErrRecordNotFound
+		}
+		copied := *link
+		return &copied, nil
+	}
+
+	// Une invalidation pendant la lecture en base (écriture concurrente) empêche la mise en cache
+	// d'une valeur peut-être déjà périmée.
+	generation := r.links.Generation(shortCode)
+	link, err := r.LinkRepository.GetLinkByShortCode(ctx, shortCode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if r.cfg.NegativeTTL > 0 {
+			r.links.SetIfGeneration(shortCode, nil, r.cfg.NegativeTTL, generation)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Le cache conserve sa propre copie : l'appelant peut modifier le lien retourné.
+	cached := *link
+	r.links.SetIfGeneration(shortCode, &cached, r.cfg.TTL, generation)
+	return link, nil
+}
+
+// CreateLink invalide un éventuel cache négatif du code attribué.
+func (r *CachedLinkRepository) CreateLink(ctx context.Context, link *models.Link) error {
+	err := r.LinkRepository.CreateLink(ctx, link)
+	r.links.Delete(link.ShortCode)
+	return err
+}
+
+func (r *CachedLinkRepository) UpdateLink(ctx context.Context, link *models.Link, fields map[string]interface{}) error {
+	err := r.LinkRepository.UpdateLink(ctx, link, fields)
+	r.links.Delete(link.ShortCode)
+	return err
+}
+
+func (r *CachedLinkRepository) DeleteLink(ctx context.Context, link *models.Link) error {
+	err := r.LinkRepository.DeleteLink(ctx, link)
+	r.links.Delete(link.ShortCode)
+	return err
+}
+
+func (r *CachedLinkRepository) SetLinkBlocked(ctx context.Context, id uint, blockedAt *time.Time, reason string) error {
+	err := r.LinkRepository.SetLinkBlocked(ctx, id, blockedAt, reason)
+	r.invalidateID(id)
+	return err
+}
+
+func (r *CachedLinkRepository) SetLinkDisabled(ctx context.Context, id uint, disabledAt *time.Time, reason string) error {
+	err := r.LinkRepository.SetLinkDisabled(ctx, id, disabledAt, reason)
+	r.invalidateID(id)
+	return err
+}
+
+// MarkExpiredLinks vide le cache si des liens ont été expirés : la requête ne dit pas lesquels.
+func (r *CachedLinkRepository) MarkExpiredLinks(ctx context.Context, now time.Time) (int64, error) {
+	count, err := r.LinkRepository.MarkExpiredLinks(ctx, now)
+	if count > 0 {
+		r.links.Purge()
+	}
+	return count, err
+}
+
+// Relecture des modifications de liens. Elle suit l'identifiant auto-incrémenté de la table, qui ne dépend
+// d'aucune horloge. Un identifiant peut toutefois devenir visible après un identifiant supérieur (transactions
+// validées dans le désordre) : les identifiants sautés sont relus pendant 'linkChangeGapTimeout', au-delà
+// duquel ils sont considérés comme abandonnés (transaction annulée, valeurs de séquence perdues).
+const (
+	linkChangeGapTimeout = time.Minute
+	linkChangeMaxGaps    = 1000 // Au-delà, un saut d'identifiants n'est pas suivi
+	linkChangeRetention  = time.Hour
+	linkChangePruneEvery = time.Minute
+)
+
+// WatchChanges relit toutes les 'interval' les modifications de liens enregistrées par tous les processus
+// et retire du cache les liens concernés, jusqu'à l'annulation de 'ctx'. Les modifications plus anciennes
+// que 'linkChangeRetention' sont supprimées au passage.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (r *CachedLinkRepository) WatchChanges(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Le cache est vide au démarrage : seules les modifications suivantes comptent.
+	lastID, err := r.LinkRepository.LastLinkChangeID(ctx)
+	started := err == nil
+	if err != nil {
+		slog.Warn("Lecture des modifications de liens échouée", "component", "cache", "error", err)
+	}
+	gaps := make(map[uint]time.Time) // Identifiants sautés inférieurs à lastID, avec la date de leur constat
+	var lastPrune time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		if !started {
+			if lastID, err = r.LinkRepository.LastLinkChangeID(ctx); err != nil {
+				slog.Warn("Lecture des modifications de liens échouée", "component", "cache", "error", err)
+				continue
+			}
+			// Des lectures ont pu être mises en cache entre-temps sans être suivies.
+			r.links.Purge()
+			started = true
+		}
+
+		missing := make([]uint, 0, len(gaps))
+		for id := range gaps {
+			missing = append(missing, id)
+		}
+		changes, err := r.LinkRepository.ListLinkChanges(ctx, lastID, missing)
+		if err != nil {
+			slog.Warn("Lecture des modifications de liens échouée", "component", "cache", "error", err)
+			continue
+		}
+		for _, change := range changes {
+			if change.ID > lastID {
+				if skipped := change.ID - lastID - 1; skipped > 0 && skipped <= linkChangeMaxGaps {
+					for id := lastID + 1; id < change.ID; id++ {
+						gaps[id] = now
+					}
+				}
+				lastID = change.ID
+			} else {
+				delete(gaps, change.ID)
+			}
+			switch {
+			case change.ShortCode != "":
+				r.links.Delete(change.ShortCode)
+			case change.LinkID != 0:
+				r.invalidateID(change.LinkID)
+			default:
+				r.links.Purge()
+			}
+		}
+		for id, seenAt := range gaps {
+			if now.Sub(seenAt) > linkChangeGapTimeout {
+				delete(gaps, id)
+			}
+		}
+
+		if now.Sub(lastPrune) >= linkChangePruneEvery {
+			lastPrune = now
+			if _, err := r.LinkRepository.PruneLinkChanges(ctx, now.Add(-linkChangeRetention)); err != nil {
+				slog.Warn("Purge des modifications de liens échouée", "component", "cache", "error", err)
+			}
+		}
+	}
+}
+
+// invalidateID retire du cache le lien d'identifiant 'id'.
+func (r *CachedLinkRepository) invalidateID(id uint) {
+	r.links.DeleteFunc(func(_ string, link *models.Link) bool {
+		return link != nil && link.ID == id
+	})
+}
+
+// Stats retourne les compteurs du cache.
+func (r *CachedLinkRepository) Stats() LinkCacheStats {
+	return LinkCacheStats{Stats: r.links.Stats(), NegativeHits: r.negativeHits.Load()}
+}
+
+// LogStats journalise les compteurs du cache toutes les 'interval' jusqu'à l'annulation de 'ctx'.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (r *CachedLinkRepository) LogStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := r.Stats()
+			slog.Info("Statistiques du cache des liens", "component", "cache",
+				"hits", stats.Hits, "negative_hits", stats.NegativeHits, "misses", stats.Misses,
+				"hit_ratio", stats.HitRatio(), "evictions", stats.Evictions, "size", stats.Size)
+		}
+	}
+}
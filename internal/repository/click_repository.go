@@ -1,15 +1,63 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Granularités supportées pour l'agrégation temporelle des clics.
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// bucketExpressions associe, pour chaque dialecte SQL, chaque granularité à l'expression qui calcule
+// le début (UTC) de l'intervalle contenant le clic. Les semaines commencent le lundi.
+var bucketExpressions = map[string]map[string]string{
+	"sqlite": {
+		IntervalHour: "strftime('%Y-%m-%d %H:00:00', timestamp)",
+		IntervalDay:  "date(timestamp)",
+		IntervalWeek: "date(timestamp, 'weekday 0', '-6 days')",
+	},
+	"postgres": {
+		IntervalHour: `to_char(date_trunc('hour', "timestamp" AT TIME ZONE 'UTC'), 'YYYY-MM-DD HH24:MI:SS')`,
+		IntervalDay:  `to_char(date_trunc('day', "timestamp" AT TIME ZONE 'UTC'), 'YYYY-MM-DD')`,
+		IntervalWeek: `to_char(date_trunc('week', "timestamp" AT TIME ZONE 'UTC'), 'YYYY-MM-DD')`,
+	},
+	"mysql": {
+		IntervalHour: "DATE_FORMAT(`timestamp`, '%Y-%m-%d %H:00:00')",
+		IntervalDay:  "DATE_FORMAT(`timestamp`, '%Y-%m-%d')",
+		IntervalWeek: "DATE_FORMAT(DATE_SUB(`timestamp`, INTERVAL WEEKDAY(`timestamp`) DAY), '%Y-%m-%d')",
+	},
+}
+
+// Dimensions sur lesquelles les clics peuvent être ventilés.
+const (
+	DimensionReferrer = "referrer_host"
+	DimensionBrowser  = "browser"
+	DimensionOS       = "os"
+	DimensionDevice   = "device"
+)
+
+var breakdownDimensions = map[string]struct{}{
+	DimensionReferrer: {},
+	DimensionBrowser:  {},
+	DimensionOS:       {},
+	DimensionDevice:   {},
+}
+
 type ClickRepository interface {
-	CreateClick(click *models.Click) error
-	CountClicksByLinkID(linkID uint) (int, error)
+	CreateClick(ctx context.Context, click *models.Click) error
+	CreateClicks(ctx context.Context, clicks []*models.Click) error
+	CountClicksByLinkID(ctx context.Context, linkID uint) (int, error)
+	CountClicksByInterval(ctx context.Context, linkID uint, from, to time.Time, interval string) ([]models.ClickBucket, error)
+	CountClicksByDimension(ctx context.Context, linkID uint, dimension string, limit int) ([]models.ClickCount, error)
 }
 
 type GormClickRepository struct {
@@ -20,19 +68,101 @@ func NewClickRepository(db *gorm.DB) *GormClickRepository {
 	return &GormClickRepository{db: db}
 }
 
-func (r *GormClickRepository) CreateClick(click *models.Click) error {
-	result := r.db.Create(click)
+func (r *GormClickRepository) CreateClick(ctx context.Context, click *models.Click) error {
+	result := r.db.WithContext(ctx).Create(click)
 	if result.Error != nil {
 		return fmt.Errorf("failed to create click: %w", result.Error)
 	}
 	return nil
 }
 
-func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
+// CreateClicks insère un lot de clics en une seule transaction.
+func (r *GormClickRepository) CreateClicks(ctx context.Context, clicks []*models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	result := r.db.WithContext(ctx).Create(&clicks)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create %d clicks: %w", len(clicks), result.Error)
+	}
+	return nil
+}
+
+func (r *GormClickRepository) CountClicksByLinkID(ctx context.Context, linkID uint) (int, error) {
 	var count int64
-	result := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count)
+	result := r.db.WithContext(ctx).Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count)
 	if result.Error != nil {
 		return 0, fmt.Errorf("failed to count clicks for link ID %d: %w", linkID, result.Error)
 	}
 	return int(count), nil
 }
+
+// CountClicksByInterval compte les clics d'un lien entre 'from' (inclus) et 'to' (exclu),
+// regroupés par heure, jour ou semaine. Seuls les intervalles contenant au moins un clic sont retournés.
+func (r *GormClickRepository) CountClicksByInterval(ctx context.Context, linkID uint, from, to time.Time, interval string) ([]models.ClickBucket, error) {
+	expressions, ok := bucketExpressions[r.db.Dialector.Name()]
+	if !ok {
+		return nil, fmt.Errorf("click aggregation is not supported on %s", r.db.Dialector.Name())
+	}
+	expr, ok := expressions[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %q", interval)
+	}
+
+	var rows []struct {
+		Bucket string
+		Count  int64
+	}
+	result := r.db.WithContext(ctx).Model(&models.Click{}).
+		Select(expr+" AS bucket, COUNT(*) AS count").
+		Where("link_id = ? AND ? >= ? AND ? < ?", linkID, clause.Column{Name: "timestamp"}, from.UTC(),
+			clause.Column{Name: "timestamp"}, to.UTC()).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to aggregate clicks for link ID %d: %w", linkID, result.Error)
+	}
+
+	buckets := make([]models.ClickBucket, 0, len(rows))
+	for _, row := range rows {
+		start, err := parseBucket(row.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, models.ClickBucket{Start: start, Count: row.Count})
+	}
+	return buckets, nil
+}
+
+// CountClicksByDimension retourne les 'limit' valeurs les plus fréquentes de 'dimension'
+// (référent, navigateur, OS ou appareil) pour un lien, triées par nombre de clics décroissant.
+func (r *GormClickRepository) CountClicksByDimension(ctx context.Context, linkID uint, dimension string, limit int) ([]models.ClickCount, error) {
+	if _, ok := breakdownDimensions[dimension]; !ok {
+		return nil, fmt.Errorf("unsupported click dimension %q", dimension)
+	}
+
+	counts := []models.ClickCount{}
+	result := r.db.WithContext(ctx).Model(&models.Click{}).
+		Select(dimension+" AS value, COUNT(*) AS count").
+		Where("link_id = ?", linkID).
+		Group(dimension).
+		Order("count DESC").
+		Order("value").
+		Limit(limit).
+		Scan(&counts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count clicks by %s for link ID %d: %w", dimension, linkID, result.Error)
+	}
+	return counts, nil
+}
+
+// parseBucket convertit la clé d'intervalle produite par la base en time.Time UTC.
+func parseBucket(bucket string) (time.Time, error) {
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, bucket, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected click bucket format %q", bucket)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrDestinationChanged indique que la destination du lien a changé pendant son contrôle : le résultat est périmé.
+var ErrDestinationChanged = errors.New("link destination changed during check")
+
+type HealthRepository interface {
+	SaveCheck(ctx context.Context, check *models.HealthCheck, health *models.LinkHealth, longURL string) error
+	GetLinkHealth(ctx context.Context, linkID uint) (*models.LinkHealth, error)
+	GetAllLinkHealth(ctx context.Context) ([]models.LinkHealth, error)
+	ListHealthChecks(ctx context.Context, linkID uint, limit int) ([]models.HealthCheck, error)
+	ListBrokenLinks(ctx context.Context) ([]models.LinkHealth, error)
+	PruneHealthChecks(ctx context.Context, before time.Time) (int64, error)
+}
+
+type GormHealthRepository struct {
+	db *gorm.DB
+}
+
+func NewHealthRepository(db *gorm.DB) *GormHealthRepository {
+	return &GormHealthRepository{db: db}
+}
+
+// SaveCheck enregistre un contrôle dans l'historique et met à jour l'état courant du lien, dans une même transaction.
+// 'longURL' est la destination contrôlée : si le lien a changé de destination entre-temps, rien n'est écrit
+// et ErrDestinationChanged est retournée.
+func (r *GormHealthRepository) SaveCheck(ctx context.Context, check *models.HealthCheck, health *models.LinkHealth, longURL string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int64
+		if err := tx.Model(&models.Link{}).Where("id = ? AND long_url = ?", check.LinkID, longURL).Count(&current).Error; err != nil {
+			return err
+		}
+		if current == 0 {
+			return ErrDestinationChanged
+		}
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Link").Save(health).Error
+	})
+	if errors.Is(err, ErrDestinationChanged) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save health check for link ID %d: %w", check.LinkID, err)
+	}
+	return nil
+}
+
+func (r *GormHealthRepository) GetLinkHealth(ctx context.Context, linkID uint) (*models.LinkHealth, error) {
+	var health models.LinkHealth
+	result := r.db.WithContext(ctx).Where("link_id = ?", linkID).First(&health)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &health, nil
+}
+
+func (r *GormHealthRepository) GetAllLinkHealth(ctx context.Context) ([]models.LinkHealth, error) {
+	var states []models.LinkHealth
+	result := r.db.WithContext(ctx).Find(&states)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve link health states: %w", result.Error)
+	}
+	return states, nil
+}
+
+// ListHealthChecks retourne les 'limit' contrôles les plus récents d'un lien.
+func (r *GormHealthRepository) ListHealthChecks(ctx context.Context, linkID uint, limit int) ([]models.HealthCheck, error) {
+	checks := []models.HealthCheck{}
+	result := r.db.WithContext(ctx).Where("link_id = ?", linkID).Order("checked_at DESC").Limit(limit).Find(&checks)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve health checks for link ID %d: %w", linkID, result.Error)
+	}
+	return checks, nil
+}
+
+// ListBrokenLinks retourne l'état des liens actifs dont la destination est actuellement inaccessible,
+// avec le lien associé, du plus ancien incident au plus récent.
+func (r *GormHealthRepository) ListBrokenLinks(ctx context.Context) ([]models.LinkHealth, error) {
+	var states []models.LinkHealth
+	result := r.db.WithContext(ctx).InnerJoins("Link", r.db.Where("expired_at IS NULL")).
+		Where("link_healths.accessible = ?", false).
+		Order("link_healths.last_changed_at").
+		Find(&states)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve broken links: %w", result.Error)
+	}
+	return states, nil
+}
+
+// PruneHealthChecks supprime l'historique antérieur à 'before'.
+func (r *GormHealthRepository) PruneHealthChecks(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("checked_at < ?", before).Delete(&models.HealthCheck{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune health checks: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
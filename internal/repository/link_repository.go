@@ -1,17 +1,56 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
 )
 
+// ErrDuplicateShortCode est retournée par CreateLink lorsque le code court est déjà attribué,
+// y compris à un lien supprimé logiquement (l'index unique porte sur toutes les lignes).
+// Elle suppose que la connexion GORM est ouverte avec TranslateError.
+var ErrDuplicateShortCode = errors.New("short code already exists")
+
+// LinkListOptions décrit la pagination et le tri appliqués à ListLinks.
+type LinkListOptions struct {
+	// OwnerID restreint la liste aux liens d'une clé d'API (nil = tous les liens).
+	OwnerID *uint
+	Offset  int
+	Limit   int
+	// SortBy est le nom de la colonne de tri ; il doit appartenir à SortableLinkColumns.
+	SortBy string
+	Desc   bool
+}
+
+// SortableLinkColumns liste les colonnes sur lesquelles la liste des liens peut être triée.
+var SortableLinkColumns = map[string]struct{}{
+	"id":         {},
+	"short_code": {},
+	"long_url":   {},
+	"created_at": {},
+	"expires_at": {},
+}
+
 type LinkRepository interface {
-	CreateLink(link *models.Link) error
-	GetLinkByShortCode(shortCode string) (*models.Link, error)
-	GetAllLinks() ([]models.Link, error)
-	CountClicksByLinkID(linkID uint) (int, error)
+	CreateLink(ctx context.Context, link *models.Link) error
+	GetLinkByShortCode(ctx context.Context, shortCode string) (*models.Link, error)
+	GetLinkByID(ctx context.Context, id uint) (*models.Link, error)
+	GetAllLinks(ctx context.Context) ([]models.Link, error)
+	GetActiveLinks(ctx context.Context) ([]models.Link, error)
+	ListLinks(ctx context.Context, opts LinkListOptions) ([]models.Link, int64, error)
+	UpdateLink(ctx context.Context, link *models.Link, fields map[string]interface{}) error
+	DeleteLink(ctx context.Context, link *models.Link) error
+	CountClicksByLinkID(ctx context.Context, linkID uint) (int, error)
+	MarkExpiredLinks(ctx context.Context, now time.Time) (int64, error)
+	SetLinkBlocked(ctx context.Context, id uint, blockedAt *time.Time, reason string) error
+	SetLinkDisabled(ctx context.Context, id uint, disabledAt *time.Time, reason string) error
+	LastLinkChangeID(ctx context.Context) (uint, error)
+	ListLinkChanges(ctx context.Context, afterID uint, missingIDs []uint) ([]models.LinkChange, error)
+	PruneLinkChanges(ctx context.Context, before time.Time) (int64, error)
 }
 
 type GormLinkRepository struct {
@@ -22,35 +61,226 @@ func NewLinkRepository(db *gorm.DB) *GormLinkRepository {
 	return &GormLinkRepository{db: db}
 }
 
-func (r *GormLinkRepository) CreateLink(link *models.Link) error {
-	result := r.db.Create(link)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create link: %w", result.Error)
+// CreateLink crée le lien et enregistre sa création, pour invalider un éventuel cache négatif de son code.
+func (r *GormLinkRepository) CreateLink(ctx context.Context, link *models.Link) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		return recordChange(tx, 0, link.ShortCode)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateShortCode
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create link: %w", err)
 	}
 	return nil
 }
 
-func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+func (r *GormLinkRepository) GetLinkByShortCode(ctx context.Context, shortCode string) (*models.Link, error) {
 	var link models.Link
-	result := r.db.Where("short_code = ?", shortCode).First(&link)
+	result := r.db.WithContext(ctx).Where("short_code = ?", shortCode).First(&link)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &link, nil
 }
 
-func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
+// GetLinkByID lit le lien en base ; il n'est jamais servi par le cache, pour préparer une écriture.
+func (r *GormLinkRepository) GetLinkByID(ctx context.Context, id uint) (*models.Link, error) {
+	var link models.Link
+	result := r.db.WithContext(ctx).First(&link, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &link, nil
+}
+
+func (r *GormLinkRepository) GetAllLinks(ctx context.Context) ([]models.Link, error) {
 	var links []models.Link
-	result := r.db.Find(&links)
+	result := r.db.WithContext(ctx).Find(&links)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to retrieve all links: %w", result.Error)
 	}
 	return links, nil
 }
 
-func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
+// GetActiveLinks retourne les liens qui ne sont ni expirés ni désactivés par un administrateur.
+func (r *GormLinkRepository) GetActiveLinks(ctx context.Context) ([]models.Link, error) {
+	var links []models.Link
+	result := r.db.WithContext(ctx).Where("expired_at IS NULL AND disabled_at IS NULL").Find(&links)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve active links: %w", result.Error)
+	}
+	return links, nil
+}
+
+// ListLinks retourne une page de liens ainsi que le nombre total de liens.
+func (r *GormLinkRepository) ListLinks(ctx context.Context, opts LinkListOptions) ([]models.Link, int64, error) {
+	if _, ok := SortableLinkColumns[opts.SortBy]; !ok {
+		return nil, 0, fmt.Errorf("invalid sort column %q", opts.SortBy)
+	}
+
+	query := r.db.WithContext(ctx).Model(&models.Link{})
+	if opts.OwnerID != nil {
+		query = query.Where("owner_id = ?", *opts.OwnerID)
+	}
+
+	var total int64
+	if result := query.Count(&total); result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to count links: %w", result.Error)
+	}
+
+	order := opts.SortBy
+	if opts.Desc {
+		order += " DESC"
+	}
+
+	var links []models.Link
+	result := query.Order(order).Order("id").Offset(opts.Offset).Limit(opts.Limit).Find(&links)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to list links: %w", result.Error)
+	}
+	return links, total, nil
+}
+
+// UpdateLink n'écrit que les colonnes 'fields' du lien : les autres (désactivation, blocage, expiration)
+// ont pu être modifiées entre-temps par un autre processus et ne doivent pas être écrasées.
+func (r *GormLinkRepository) UpdateLink(ctx context.Context, link *models.Link, fields map[string]interface{}) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Link{}).Where("id = ?", link.ID).Updates(fields).Error; err != nil {
+			return err
+		}
+		if _, ok := fields["long_url"]; ok {
+			// L'état de santé portait sur l'ancienne destination : la nouvelle repart d'un état inconnu.
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkHealth{}).Error; err != nil {
+				return err
+			}
+		}
+		return recordChange(tx, 0, link.ShortCode)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update link %d: %w", link.ID, err)
+	}
+	return nil
+}
+
+// DeleteLink supprime logiquement le lien (renseigne DeletedAt).
+func (r *GormLinkRepository) DeleteLink(ctx context.Context, link *models.Link) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(link).Error; err != nil {
+			return err
+		}
+		return recordChange(tx, 0, link.ShortCode)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete link %d: %w", link.ID, err)
+	}
+	return nil
+}
+
+// MarkExpiredLinks marque comme expirés les liens dont la date d'expiration est dépassée
+// ou dont le quota de clics est atteint. Retourne le nombre de liens nouvellement expirés.
+// La requête ne dit pas quels liens ont expiré : la modification enregistrée vide les caches.
+func (r *GormLinkRepository) MarkExpiredLinks(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Link{}).
+			Where("expired_at IS NULL").
+			Where(tx.Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+				Or("max_clicks > 0 AND (SELECT COUNT(*) FROM clicks WHERE clicks.link_id = links.id) >= max_clicks")).
+			Update("expired_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		count = result.RowsAffected
+		if count == 0 {
+			return nil
+		}
+		return recordChange(tx, 0, "")
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark expired links: %w", err)
+	}
+	return count, nil
+}
+
+// SetLinkBlocked bloque le lien (blockedAt non nil) ou lève le blocage (blockedAt nil).
+func (r *GormLinkRepository) SetLinkBlocked(ctx context.Context, id uint, blockedAt *time.Time, reason string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Link{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"blocked_at": blockedAt, "blocked_reason": reason}).Error
+		if err != nil {
+			return err
+		}
+		return recordChange(tx, id, "")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update block status of link %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetLinkDisabled désactive le lien (disabledAt non nil) ou le réactive (disabledAt nil).
+func (r *GormLinkRepository) SetLinkDisabled(ctx context.Context, id uint, disabledAt *time.Time, reason string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Link{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"disabled_at": disabledAt, "disabled_reason": reason}).Error
+		if err != nil {
+			return err
+		}
+		return recordChange(tx, id, "")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update disabled status of link %d: %w", id, err)
+	}
+	return nil
+}
+
+// LastLinkChangeID retourne l'identifiant de la dernière modification de lien enregistrée (0 si aucune).
+func (r *GormLinkRepository) LastLinkChangeID(ctx context.Context) (uint, error) {
+	var id uint
+	result := r.db.WithContext(ctx).Model(&models.LinkChange{}).Select("COALESCE(MAX(id), 0)").Scan(&id)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to read last link change: %w", result.Error)
+	}
+	return id, nil
+}
+
+// ListLinkChanges retourne, par identifiant croissant, les modifications de liens postérieures à 'afterID'
+// ainsi que celles des identifiants 'missingIDs' (attribués mais pas encore visibles lors d'une lecture précédente).
+func (r *GormLinkRepository) ListLinkChanges(ctx context.Context, afterID uint, missingIDs []uint) ([]models.LinkChange, error) {
+	var changes []models.LinkChange
+	query := r.db.WithContext(ctx).Where("id > ?", afterID)
+	if len(missingIDs) > 0 {
+		query = query.Or("id IN ?", missingIDs)
+	}
+	result := query.Order("id").Find(&changes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list link changes: %w", result.Error)
+	}
+	return changes, nil
+}
+
+// PruneLinkChanges supprime les modifications de liens enregistrées avant 'before'.
+func (r *GormLinkRepository) PruneLinkChanges(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.LinkChange{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune link changes: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// recordChange enregistre la modification d'un lien dans la transaction 'tx' : les serveurs qui la lisent
+// retirent le lien de leur cache (voir CachedLinkRepository.WatchChanges).
+func recordChange(tx *gorm.DB, linkID uint, shortCode string) error {
+	return tx.Create(&models.LinkChange{LinkID: linkID, ShortCode: shortCode}).Error
+}
+
+func (r *GormLinkRepository) CountClicksByLinkID(ctx context.Context, linkID uint) (int, error) {
 	var count int64
-	result := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count)
+	result := r.db.WithContext(ctx).Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count)
 	if result.Error != nil {
 		return 0, fmt.Errorf("failed to count clicks for link ID %d: %w", linkID, result.Error)
 	}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+type SequenceRepository interface {
+	NextValue(ctx context.Context, name string) (uint64, error)
+}
+
+type GormSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewSequenceRepository(db *gorm.DB) *GormSequenceRepository {
+	return &GormSequenceRepository{db: db}
+}
+
+// maxSequenceAttempts borne les essais de NextValue. Un conflit ne se produit qu'à la création de la séquence :
+// l'essai suivant trouve la ligne et l'incrémente. Des conflits répétés signalent un problème de contrainte.
+const maxSequenceAttempts = 3
+
+// NextValue incrémente atomiquement la séquence 'name' (créée à 1 si elle n'existe pas) et retourne sa nouvelle valeur.
+func (r *GormSequenceRepository) NextValue(ctx context.Context, name string) (uint64, error) {
+	var err error
+	for attempt := 0; attempt < maxSequenceAttempts; attempt++ {
+		var value uint64
+		value, err = r.increment(ctx, name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			break
+		}
+		// Une autre instance vient de créer la séquence : on réessaie l'incrément.
+	}
+	return 0, fmt.Errorf("failed to increment sequence %s: %w", name, err)
+}
+
+// increment incrémente la séquence 'name' dans une transaction, ou la crée à 1.
+func (r *GormSequenceRepository) increment(ctx context.Context, name string) (uint64, error) {
+	var value uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Sequence{}).Where("name = ?", name).
+			Update("value", gorm.Expr("value + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			seq := models.Sequence{Name: name, Value: 1}
+			if err := tx.Create(&seq).Error; err != nil {
+				return err
+			}
+			value = seq.Value
+			return nil
+		}
+
+		var seq models.Sequence
+		if err := tx.Where("name = ?", name).First(&seq).Error; err != nil {
+			return err
+		}
+		value = seq.Value
+		return nil
+	})
+	return value, err
+}
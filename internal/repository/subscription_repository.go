@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+type SubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.LinkSubscription) error
+	ListSubscriptionsByLink(ctx context.Context, linkID uint) ([]models.LinkSubscription, error)
+	DeleteSubscription(ctx context.Context, id, linkID uint) error
+}
+
+type GormSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) *GormSubscriptionRepository {
+	return &GormSubscriptionRepository{db: db}
+}
+
+func (r *GormSubscriptionRepository) CreateSubscription(ctx context.Context, sub *models.LinkSubscription) error {
+	result := r.db.WithContext(ctx).Omit("Link").Create(sub)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create subscription: %w", result.Error)
+	}
+	return nil
+}
+
+func (r *GormSubscriptionRepository) ListSubscriptionsByLink(ctx context.Context, linkID uint) ([]models.LinkSubscription, error) {
+	subs := []models.LinkSubscription{}
+	result := r.db.WithContext(ctx).Where("link_id = ?", linkID).Order("id").Find(&subs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve subscriptions for link ID %d: %w", linkID, result.Error)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription supprime l'abonnement 'id' du lien 'linkID'.
+// Retourne gorm.ErrRecordNotFound si l'abonnement n'appartient pas à ce lien.
+func (r *GormSubscriptionRepository) DeleteSubscription(ctx context.Context, id, linkID uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND link_id = ?", id, linkID).Delete(&models.LinkSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete subscription %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// MaxReportDetailsLength est la taille maximale de la description d'un signalement.
+const MaxReportDetailsLength = 2000
+
+var (
+	// ErrInvalidCategory est retournée pour une catégorie de signalement inconnue.
+	ErrInvalidCategory = errors.New("category must be one of: phishing, malware, spam, illegal, other")
+	// ErrInvalidReporterEmail est retournée lorsque l'adresse de contact est invalide.
+	ErrInvalidReporterEmail = errors.New("email must be a valid e-mail address")
+	// ErrReasonRequired est retournée lorsqu'un lien est désactivé sans motif.
+	ErrReasonRequired = errors.New("a reason is required")
+	// ErrLinkNotDisabled est retournée lors de la réactivation d'un lien qui n'est pas désactivé.
+	ErrLinkNotDisabled = errors.New("link is not disabled")
+)
+
+// AbuseService reçoit les signalements d'abus et permet aux administrateurs de les traiter
+// en désactivant ou en réactivant les liens concernés.
+type AbuseService struct {
+	abuseRepo repository.AbuseRepository
+	linkRepo  repository.LinkRepository
+}
+
+func NewAbuseService(abuseRepo repository.AbuseRepository, linkRepo repository.LinkRepository) *AbuseService {
+	return &AbuseService{
+		abuseRepo: abuseRepo,
+		linkRepo:  linkRepo,
+	}
+}
+
+// ReportLink enregistre un signalement public. Un second signalement en attente de la même adresse IP
+// pour le même lien est ignoré : le signalement existant est conservé et nil est retourné.
+func (s *AbuseService) ReportLink(ctx context.Context, shortCode, category, details, email, reporterIP string) (*models.AbuseReport, error) {
+	switch category {
+	case models.AbusePhishing, models.AbuseMalware, models.AbuseSpam, models.AbuseIllegal, models.AbuseOther:
+	default:
+		return nil, ErrInvalidCategory
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil || len(email) > 254 {
+			return nil, ErrInvalidReporterEmail
+		}
+	}
+	details = strings.TrimSpace(details)
+	if len(details) > MaxReportDetailsLength {
+		details = details[:MaxReportDetailsLength]
+	}
+
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate, err := s.abuseRepo.HasOpenReport(ctx, link.ID, reporterIP)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return nil, nil
+	}
+
+	report := &models.AbuseReport{
+		LinkID:        link.ID,
+		Category:      category,
+		Details:       details,
+		ReporterEmail: email,
+		ReporterIP:    reporterIP,
+		Status:        models.ReportOpen,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.abuseRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	slog.Info("Signalement d'abus reçu", "component", "abuse", "report_id", report.ID, "short_code", link.ShortCode, "category", category)
+	return report, nil
+}
+
+// ListReports retourne les signalements dans l'état 'status' (vide = tous), éventuellement restreints à un lien.
+func (s *AbuseService) ListReports(ctx context.Context, status, shortCode string, limit int) ([]models.AbuseReport, error) {
+	filter := repository.AbuseReportFilter{Status: status, Limit: limit}
+	if shortCode != "" {
+		link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
+		if err != nil {
+			return nil, err
+		}
+		filter.LinkID = &link.ID
+	}
+	return s.abuseRepo.ListReports(ctx, filter)
+}
+
+// DismissReport rejette un signalement en attente ; le lien reste actif.
+func (s *AbuseService) DismissReport(ctx context.Context, id uint, resolution string) error {
+	return s.abuseRepo.ResolveReport(ctx, id, models.ReportDismissed, resolution, time.Now())
+}
+
+// DisableLink désactive un lien pour le motif 'reason' et clôt ses signalements en attente.
+// Retourne le lien et le nombre de signalements clos.
+func (s *AbuseService) DisableLink(ctx context.Context, shortCode, reason string) (*models.Link, int64, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, 0, ErrReasonRequired
+	}
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	if err := s.linkRepo.SetLinkDisabled(ctx, link.ID, &now, reason); err != nil {
+		return nil, 0, err
+	}
+	link.DisabledAt = &now
+	link.DisabledReason = reason
+
+	resolved, err := s.abuseRepo.ResolveLinkReports(ctx, link.ID, models.ReportActioned, reason, now)
+	if err != nil {
+		return link, 0, fmt.Errorf("link disabled but reports could not be closed: %w", err)
+	}
+	slog.Warn("Lien désactivé", "component", "abuse", "short_code", link.ShortCode, "reason", reason)
+	return link, resolved, nil
+}
+
+// RestoreLink réactive un lien désactivé.
+func (s *AbuseService) RestoreLink(ctx context.Context, shortCode string) (*models.Link, error) {
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if link.DisabledAt == nil {
+		return nil, ErrLinkNotDisabled
+	}
+
+	if err := s.linkRepo.SetLinkDisabled(ctx, link.ID, nil, ""); err != nil {
+		return nil, err
+	}
+	slog.Info("Lien réactivé", "component", "abuse", "short_code", link.ShortCode, "disabled_reason", link.DisabledReason)
+	link.DisabledAt = nil
+	link.DisabledReason = ""
+	return link, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/shortcode"
+)
+
+// Format des clés générées : "usk_" suivi de 32 caractères alphanumériques.
+const (
+	apiKeyPrefix       = "usk_"
+	apiKeyRandomLength = 32
+	apiKeyDisplayChars = 8
+)
+
+// lastUsedResolution évite d'écrire en base à chaque requête authentifiée.
+const lastUsedResolution = time.Minute
+
+var (
+	// ErrInvalidAPIKey est retournée lorsque la clé est inconnue ou révoquée.
+	ErrInvalidAPIKey = errors.New("invalid or revoked API key")
+	// ErrAPIKeyNameRequired est retournée lorsqu'une clé est créée sans nom.
+	ErrAPIKeyNameRequired = errors.New("API key name is required")
+)
+
+// APIKeyService gère la création, la révocation et la vérification des clés d'API.
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService crée et retourne une nouvelle instance d'APIKeyService.
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey génère une nouvelle clé et n'en stocke que l'empreinte.
+// La clé en clair est retournée une seule fois et ne pourra plus être retrouvée.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, name string) (string, *models.APIKey, error) {
+	if name == "" {
+		return "", nil, ErrAPIKeyNameRequired
+	}
+
+	generator := shortcode.RandomGenerator{Length: apiKeyRandomLength, Alphabet: shortcode.DefaultAlphabet}
+	random, err := generator.Generate(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := apiKeyPrefix + random
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    plaintext[:len(apiKeyPrefix)+apiKeyDisplayChars],
+		KeyHash:   hashAPIKey(plaintext),
+		CreatedAt: time.Now(),
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return plaintext, key, nil
+}
+
+// Authenticate retourne la clé correspondant à 'plaintext' si elle existe et n'est pas révoquée.
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*models.APIKey, error) {
+	if plaintext == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(ctx, hashAPIKey(plaintext))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.apiKeyRepo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			// Non bloquant : la date de dernière utilisation n'est qu'indicative.
+			slog.WarnContext(ctx, "Failed to update API key last use", "key_id", key.ID, "error", err)
+		}
+	}
+	return key, nil
+}
+
+// ListAPIKeys retourne toutes les clés (actives et révoquées).
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.apiKeyRepo.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey révoque la clé d'identifiant 'id'. Les liens qu'elle possède restent redirigés.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, id, now); err != nil {
+		return nil, err
+	}
+	key.RevokedAt = &now
+	return key, nil
+}
+
+// hashAPIKey calcule l'empreinte SHA-256 (hexadécimale) d'une clé.
+// Les clés étant aléatoires et longues, un hachage lent de type bcrypt n'est pas nécessaire.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
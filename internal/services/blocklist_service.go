@@ -0,0 +1,408 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// maxBlocklistValueLength est la taille maximale d'une entrée de liste de blocage (colonne 'value').
+const maxBlocklistValueLength = 512
+
+var (
+	hostnamePattern = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_-]*[a-z0-9_])?(\.[a-z0-9_]([a-z0-9_-]*[a-z0-9_])?)*$`)
+	sha256Pattern   = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// hostsFileNames sont les noms d'hôte locaux présents dans les fichiers hosts, jamais importés.
+var hostsFileNames = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+}
+
+var (
+	// ErrDestinationBlocked est retournée (enveloppée avec le motif) lorsqu'une destination est interdite
+	// par une règle de domaine ou figure dans la liste de blocage.
+	ErrDestinationBlocked = errors.New("destination is blocked")
+	// ErrInvalidDomain est retournée pour un nom de domaine syntaxiquement invalide.
+	ErrInvalidDomain = errors.New("invalid domain name")
+	// ErrInvalidDomainAction est retournée pour une action de règle autre que "allow" ou "deny".
+	ErrInvalidDomainAction = errors.New("rule action must be allow or deny")
+	// ErrDomainRuleExists est retournée lorsqu'une règle existe déjà pour le domaine.
+	ErrDomainRuleExists = repository.ErrDuplicateDomainRule
+	// ErrInvalidSource est retournée lorsque le nom de la source importée est vide ou trop long.
+	ErrInvalidSource = errors.New("source name must be 1 to 64 characters long")
+)
+
+// ImportResult résume l'import d'un fichier de liste de blocage.
+type ImportResult struct {
+	Parsed  int   // Entrées valides lues dans le fichier
+	Added   int64 // Entrées ajoutées (hors doublons)
+	Invalid int   // Lignes ignorées car non reconnues
+}
+
+// BlocklistService gère les listes d'autorisation et d'interdiction de domaines ainsi que la liste
+// de blocage locale (URLs malveillantes), et vérifie les destinations des liens.
+type BlocklistService struct {
+	repo repository.BlocklistRepository
+}
+
+func NewBlocklistService(repo repository.BlocklistRepository) *BlocklistService {
+	return &BlocklistService{repo: repo}
+}
+
+// AddDomainRule autorise ("allow") ou interdit ("deny") un domaine et ses sous-domaines.
+func (s *BlocklistService) AddDomainRule(ctx context.Context, domain, action, reason string) (*models.DomainRule, error) {
+	if action != models.DomainRuleAllow && action != models.DomainRuleDeny {
+		return nil, ErrInvalidDomainAction
+	}
+	domain = normalizeHost(domain)
+	if !hostnamePattern.MatchString(domain) || len(domain) > 253 {
+		return nil, ErrInvalidDomain
+	}
+
+	rule := &models.DomainRule{
+		Domain:    domain,
+		Action:    action,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateDomainRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// RemoveDomainRule supprime la règle d'un domaine (gorm.ErrRecordNotFound si elle n'existe pas).
+func (s *BlocklistService) RemoveDomainRule(ctx context.Context, domain string) error {
+	return s.repo.DeleteDomainRule(ctx, normalizeHost(domain))
+}
+
+func (s *BlocklistService) ListDomainRules(ctx context.Context) ([]models.DomainRule, error) {
+	return s.repo.ListDomainRules(ctx)
+}
+
+func (s *BlocklistService) ListSources(ctx context.Context) ([]repository.BlocklistSource, error) {
+	return s.repo.ListSources(ctx)
+}
+
+// Import lit une liste de blocage et l'enregistre sous le nom 'source'. Formats reconnus, un par ligne :
+//   - fichier hosts ("0.0.0.0 evil.example") ou nom d'hôte seul ;
+//   - préfixe d'URL, avec ou sans schéma ("https://evil.example/phish") ;
+//   - empreinte SHA-256 en hexadécimal d'un nom d'hôte ou d'une URL.
+//
+// Les lignes vides et les commentaires ('#' ou '!') sont ignorés. Avec 'replace', les entrées
+// précédemment importées depuis la même source sont remplacées.
+func (s *BlocklistService) Import(ctx context.Context, r io.Reader, source string, replace bool) (ImportResult, error) {
+	var res ImportResult
+	if source == "" || len(source) > 64 {
+		return res, ErrInvalidSource
+	}
+
+	var entries []models.BlocklistEntry
+	seen := make(map[string]struct{})
+	now := time.Now()
+	add := func(kind, value string) {
+		if value == "" || len(value) > maxBlocklistValueLength {
+			res.Invalid++
+			return
+		}
+		if _, dup := seen[kind+" "+value]; dup {
+			return
+		}
+		seen[kind+" "+value] = struct{}{}
+		entries = append(entries, models.BlocklistEntry{Kind: kind, Value: value, Source: source, CreatedAt: now})
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+
+		// Format hosts : une adresse IP suivie d'un ou plusieurs noms d'hôte.
+		if _, err := netip.ParseAddr(fields[0]); err == nil && len(fields) > 1 {
+			for _, name := range fields[1:] {
+				host := normalizeHost(name)
+				if _, local := hostsFileNames[host]; local {
+					continue
+				}
+				if !hostnamePattern.MatchString(host) {
+					res.Invalid++
+					continue
+				}
+				add(models.BlocklistHost, host)
+			}
+			continue
+		}
+		if len(fields) != 1 {
+			res.Invalid++
+			continue
+		}
+
+		value := fields[0]
+		switch {
+		case sha256Pattern.MatchString(strings.ToLower(value)):
+			add(models.BlocklistHash, strings.ToLower(value))
+		case strings.Contains(value, "/"):
+			if !strings.Contains(value, "://") {
+				value = "http://" + value
+			}
+			u, err := url.Parse(value)
+			if err != nil || u.Host == "" {
+				res.Invalid++
+				continue
+			}
+			add(models.BlocklistPrefix, urlTarget(u))
+		default:
+			host := normalizeHost(value)
+			if !hostnamePattern.MatchString(host) {
+				res.Invalid++
+				continue
+			}
+			add(models.BlocklistHost, host)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	res.Parsed = len(entries)
+	added, err := s.repo.ImportEntries(ctx, source, entries, replace)
+	if err != nil {
+		return res, err
+	}
+	res.Added = added
+	return res, nil
+}
+
+// blocklistLookup donne accès aux règles de domaine et à la liste de blocage : en base (le repository,
+// pour une vérification ponctuelle) ou en mémoire (BlocklistSnapshot, pour de nombreuses vérifications).
+type blocklistLookup interface {
+	FindDomainRules(ctx context.Context, domains []string) ([]models.DomainRule, error)
+	HasAllowRules(ctx context.Context) (bool, error)
+	MatchEntry(ctx context.Context, hosts, hashes, prefixes []string) (*models.BlocklistEntry, error)
+}
+
+// Check vérifie les URLs non vides avec les règles de domaine puis la liste de blocage.
+// Une destination refusée est signalée par une erreur enveloppant ErrDestinationBlocked ;
+// toute autre erreur provient de la base.
+func (s *BlocklistService) Check(ctx context.Context, urls ...string) error {
+	return checkURLs(ctx, s.repo, urls)
+}
+
+// Snapshot charge en mémoire les règles de domaine et la liste de blocage, pour vérifier de nombreuses
+// destinations sans interroger la base pour chacune (passe du moniteur).
+func (s *BlocklistService) Snapshot(ctx context.Context) (*BlocklistSnapshot, error) {
+	rules, err := s.repo.ListDomainRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &BlocklistSnapshot{
+		rules:   make(map[string]models.DomainRule, len(rules)),
+		entries: make(map[string]models.BlocklistEntry, len(entries)),
+	}
+	for _, rule := range rules {
+		snapshot.rules[rule.Domain] = rule
+		if rule.Action == models.DomainRuleAllow {
+			snapshot.hasAllowRules = true
+		}
+	}
+	for _, entry := range entries {
+		// Les entrées sont triées par ID : seule la première d'une même valeur (toutes sources confondues) est gardée.
+		if _, exists := snapshot.entries[entryKey(entry.Kind, entry.Value)]; !exists {
+			snapshot.entries[entryKey(entry.Kind, entry.Value)] = entry
+		}
+	}
+	return snapshot, nil
+}
+
+// BlocklistSnapshot est une copie en mémoire des règles de domaine et de la liste de blocage,
+// figée au moment de sa création.
+type BlocklistSnapshot struct {
+	rules         map[string]models.DomainRule // Par domaine
+	hasAllowRules bool
+	entries       map[string]models.BlocklistEntry // Par type et valeur (entryKey)
+}
+
+// Check vérifie les URLs non vides comme BlocklistService.Check, sans accès à la base.
+func (b *BlocklistSnapshot) Check(ctx context.Context, urls ...string) error {
+	return checkURLs(ctx, b, urls)
+}
+
+func (b *BlocklistSnapshot) FindDomainRules(_ context.Context, domains []string) ([]models.DomainRule, error) {
+	var rules []models.DomainRule
+	for _, domain := range domains {
+		if rule, ok := b.rules[domain]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (b *BlocklistSnapshot) HasAllowRules(context.Context) (bool, error) {
+	return b.hasAllowRules, nil
+}
+
+// MatchEntry retourne, comme le repository, l'entrée correspondante la plus anciennement importée.
+func (b *BlocklistSnapshot) MatchEntry(_ context.Context, hosts, hashes, prefixes []string) (*models.BlocklistEntry, error) {
+	var match *models.BlocklistEntry
+	find := func(kind string, values []string) {
+		for _, value := range values {
+			if entry, ok := b.entries[entryKey(kind, value)]; ok && (match == nil || entry.ID < match.ID) {
+				match = &entry
+			}
+		}
+	}
+	find(models.BlocklistHost, hosts)
+	find(models.BlocklistHash, hashes)
+	find(models.BlocklistPrefix, prefixes)
+	return match, nil
+}
+
+func entryKey(kind, value string) string {
+	return kind + " " + value
+}
+
+func checkURLs(ctx context.Context, lookup blocklistLookup, urls []string) error {
+	for _, rawURL := range urls {
+		if rawURL == "" {
+			continue
+		}
+		if err := checkURL(ctx, lookup, rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkURL(ctx context.Context, lookup blocklistLookup, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("%w: invalid URL", ErrDestinationBlocked)
+	}
+	host := normalizeHost(u.Hostname())
+	hosts := parentDomains(host)
+
+	rules, err := lookup.FindDomainRules(ctx, hosts)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	for _, rule := range rules {
+		if rule.Action == models.DomainRuleDeny {
+			return fmt.Errorf("%w: domain %q is denied", ErrDestinationBlocked, rule.Domain)
+		}
+		allowed = true
+	}
+	if !allowed {
+		hasAllowRules, err := lookup.HasAllowRules(ctx)
+		if err != nil {
+			return err
+		}
+		if hasAllowRules {
+			return fmt.Errorf("%w: domain %q is not in the allowlist", ErrDestinationBlocked, host)
+		}
+	}
+
+	target := urlTarget(u)
+	hashed := append([]string{target, u.Scheme + "://" + target}, hosts...)
+	hashes := make([]string, len(hashed))
+	for i, value := range hashed {
+		sum := sha256.Sum256([]byte(value))
+		hashes[i] = hex.EncodeToString(sum[:])
+	}
+
+	entry, err := lookup.MatchEntry(ctx, hosts, hashes, targetPrefixes(target))
+	if err != nil || entry == nil {
+		return err
+	}
+	return fmt.Errorf("%w: listed in blocklist %q (%s %s)", ErrDestinationBlocked, entry.Source, entry.Kind, entry.Value)
+}
+
+// targetPrefixes retourne les préfixes de 'target' auxquels une entrée de type préfixe peut être égale :
+// ils comprennent au moins l'hôte et le '/' qui le suit (comme toute entrée importée), s'arrêtent entre
+// deux caractères et ne dépassent pas la taille maximale d'une entrée.
+func targetPrefixes(target string) []string {
+	start := strings.IndexByte(target, '/')
+	if start < 0 {
+		return nil
+	}
+	var prefixes []string
+	for i := start + 1; i <= len(target) && i <= maxBlocklistValueLength; i++ {
+		if i == len(target) || utf8.RuneStart(target[i]) {
+			prefixes = append(prefixes, target[:i])
+		}
+	}
+	return prefixes
+}
+
+// normalizeHost met un nom d'hôte en minuscules, sans point final.
+func normalizeHost(host string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// parentDomains retourne le nom d'hôte suivi de ses domaines parents ("a.b.example" → a.b.example, b.example, example).
+// Une adresse IP est retournée seule.
+func parentDomains(host string) []string {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return []string{host}
+	}
+	domains := []string{host}
+	for i := strings.Index(host, "."); i >= 0; i = strings.Index(host, ".") {
+		host = host[i+1:]
+		if host != "" {
+			domains = append(domains, host)
+		}
+	}
+	return domains
+}
+
+// urlTarget retourne la forme canonique d'une URL comparée aux préfixes et empreintes :
+// sans schéma ni fragment, hôte en minuscules, port par défaut omis.
+func urlTarget(u *url.URL) string {
+	host := normalizeHost(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	target := host + path
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target
+}
@@ -1,10 +1,38 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
+	"github.com/axellelanca/urlshortener/internal/tracing"
+)
+
+// topReferrersLimit est le nombre de référents retournés dans la ventilation des clics.
+const topReferrersLimit = 10
+
+// directReferrer désigne les clics arrivés sans en-tête Referer.
+const directReferrer = "(direct)"
+
+// ClickBreakdown regroupe la ventilation des clics d'un lien par provenance et par type de client.
+type ClickBreakdown struct {
+	Referrers []models.ClickCount `json:"referrers"`
+	Devices   []models.ClickCount `json:"devices"`
+	Browsers  []models.ClickCount `json:"browsers"`
+	OS        []models.ClickCount `json:"os"`
+}
+
+// MaxTimeSeriesBuckets limite le nombre d'intervalles retournés par une série temporelle.
+const MaxTimeSeriesBuckets = 1000
+
+var (
+	// ErrInvalidInterval est retournée lorsque la granularité demandée n'est pas supportée.
+	ErrInvalidInterval = errors.New("interval must be one of: hour, day, week")
+	// ErrInvalidTimeRange est retournée lorsque la période demandée est vide ou trop large.
+	ErrInvalidTimeRange = fmt.Errorf("time range must be non-empty and span at most %d intervals", MaxTimeSeriesBuckets)
 )
 
 // ClickService est une structure qui fournit des méthodes pour la logique métier des clics.
@@ -22,9 +50,12 @@ func NewClickService(clickRepo repository.ClickRepository) *ClickService {
 
 // RecordClick enregistre un nouvel événement de clic dans la base de données.
 // Cette méthode est appelée par le worker asynchrone.
-func (s *ClickService) RecordClick(click *models.Click) error {
+func (s *ClickService) RecordClick(ctx context.Context, click *models.Click) error {
+	ctx, span := tracing.Start(ctx, "ClickService.RecordClick")
+	defer span.End()
+
 	// Appelle le ClickRepository pour créer l'enregistrement de clic
-	if err := s.clickRepo.CreateClick(click); err != nil {
+	if err := s.clickRepo.CreateClick(ctx, click); err != nil {
 		return fmt.Errorf("failed to record click: %w", err)
 	}
 	return nil
@@ -32,11 +63,110 @@ func (s *ClickService) RecordClick(click *models.Click) error {
 
 // GetClicksCountByLinkID récupère le nombre total de clics pour un LinkID donné.
 // Cette méthode pourrait être utilisée par le LinkService pour les statistiques, ou directement par l'API stats.
-func (s *ClickService) GetClicksCountByLinkID(linkID uint) (int, error) {
+func (s *ClickService) GetClicksCountByLinkID(ctx context.Context, linkID uint) (int, error) {
+	ctx, span := tracing.Start(ctx, "ClickService.GetClicksCountByLinkID")
+	defer span.End()
+
 	// Appelle le ClickRepository pour compter les clics par LinkID
-	count, err := s.clickRepo.CountClicksByLinkID(linkID)
+	count, err := s.clickRepo.CountClicksByLinkID(ctx, linkID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count clicks: %w", err)
 	}
 	return count, nil
 }
+
+// GetClickBreakdown calcule la ventilation des clics d'un lien : principaux référents,
+// puis répartition par appareil, navigateur et système d'exploitation.
+func (s *ClickService) GetClickBreakdown(ctx context.Context, linkID uint) (*ClickBreakdown, error) {
+	ctx, span := tracing.Start(ctx, "ClickService.GetClickBreakdown")
+	defer span.End()
+
+	referrers, err := s.clickRepo.CountClicksByDimension(ctx, linkID, repository.DimensionReferrer, topReferrersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute click breakdown: %w", err)
+	}
+	for i := range referrers {
+		if referrers[i].Value == "" {
+			referrers[i].Value = directReferrer
+		}
+	}
+
+	// Le nombre de valeurs distinctes est faible pour ces dimensions : pas de limite utile.
+	const noLimit = -1
+	breakdown := &ClickBreakdown{Referrers: referrers}
+	for dimension, target := range map[string]*[]models.ClickCount{
+		repository.DimensionDevice:  &breakdown.Devices,
+		repository.DimensionBrowser: &breakdown.Browsers,
+		repository.DimensionOS:      &breakdown.OS,
+	} {
+		counts, err := s.clickRepo.CountClicksByDimension(ctx, linkID, dimension, noLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute click breakdown: %w", err)
+		}
+		*target = counts
+	}
+	return breakdown, nil
+}
+
+// GetClickTimeSeries retourne le nombre de clics d'un lien par intervalle ('hour', 'day' ou 'week')
+// entre 'from' et 'to'. Tous les intervalles de la période sont présents, y compris ceux sans clic.
+func (s *ClickService) GetClickTimeSeries(ctx context.Context, linkID uint, from, to time.Time, interval string) ([]models.ClickBucket, error) {
+	ctx, span := tracing.Start(ctx, "ClickService.GetClickTimeSeries")
+	defer span.End()
+
+	start, ok := truncateToInterval(from.UTC(), interval)
+	if !ok {
+		return nil, ErrInvalidInterval
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	// Génère les intervalles vides avant d'interroger la base pour rejeter les périodes trop larges.
+	var buckets []models.ClickBucket
+	index := make(map[time.Time]int)
+	for t := start; t.Before(to); t = nextInterval(t, interval) {
+		if len(buckets) == MaxTimeSeriesBuckets {
+			return nil, ErrInvalidTimeRange
+		}
+		index[t] = len(buckets)
+		buckets = append(buckets, models.ClickBucket{Start: t})
+	}
+
+	counts, err := s.clickRepo.CountClicksByInterval(ctx, linkID, from, to, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build click time series: %w", err)
+	}
+	for _, c := range counts {
+		if i, ok := index[c.Start]; ok {
+			buckets[i].Count = c.Count
+		}
+	}
+	return buckets, nil
+}
+
+// truncateToInterval ramène 't' au début de l'intervalle qui le contient (les semaines commencent le lundi).
+func truncateToInterval(t time.Time, interval string) (time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case repository.IntervalHour:
+		return t.Truncate(time.Hour), true
+	case repository.IntervalDay:
+		return day, true
+	case repository.IntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // nombre de jours depuis lundi
+		return day.AddDate(0, 0, -offset), true
+	}
+	return time.Time{}, false
+}
+
+// nextInterval retourne le début de l'intervalle suivant 't'.
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case repository.IntervalHour:
+		return t.Add(time.Hour)
+	case repository.IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 0, 1)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/tracing"
+)
+
+// DeadLinkConfig est la politique globale appliquée aux liens dont la destination est hors service.
+type DeadLinkConfig struct {
+	// Policy s'applique aux liens sans politique propre (vide = keep).
+	Policy string
+	// FallbackURL est l'URL de repli des liens "fallback" qui n'en définissent pas.
+	FallbackURL string
+	// FailureThreshold est le nombre de contrôles consécutifs en échec à partir duquel la destination est jugée hors service.
+	FailureThreshold int
+}
+
+// DeadLinkDecision indique comment rediriger un lien compte tenu de l'état de sa destination.
+type DeadLinkDecision struct {
+	Policy      string             // models.DeadLinkKeep si la destination est en service
+	FallbackURL string             // Destination de repli (politique "fallback")
+	Health      *models.LinkHealth // État courant de la destination (nil si non consulté)
+}
+
+// HealthService expose l'état des destinations des liens, tel qu'enregistré par le moniteur.
+type HealthService struct {
+	healthRepo repository.HealthRepository
+	deadLinks  DeadLinkConfig
+}
+
+// NewHealthService crée et retourne une nouvelle instance de HealthService.
+func NewHealthService(healthRepo repository.HealthRepository, deadLinks DeadLinkConfig) *HealthService {
+	if deadLinks.FailureThreshold < 1 {
+		deadLinks.FailureThreshold = 1
+	}
+	return &HealthService{
+		healthRepo: healthRepo,
+		deadLinks:  deadLinks,
+	}
+}
+
+// DeadLinkAction détermine la politique à appliquer à la redirection de 'link'. La politique du lien prime
+// sur la politique globale ; elle ne s'applique qu'après FailureThreshold échecs consécutifs du moniteur
+// et cesse d'elle-même dès que la destination redevient accessible.
+func (s *HealthService) DeadLinkAction(ctx context.Context, link *models.Link) (DeadLinkDecision, error) {
+	ctx, span := tracing.Start(ctx, "HealthService.DeadLinkAction")
+	defer span.End()
+
+	policy := link.DeadLinkPolicy
+	if policy == "" {
+		policy = s.deadLinks.Policy
+	}
+	if policy == "" || policy == models.DeadLinkKeep {
+		// Aucune politique active : inutile de consulter l'état de la destination.
+		return DeadLinkDecision{Policy: models.DeadLinkKeep}, nil
+	}
+
+	health, err := s.GetLinkStatus(ctx, link.ID)
+	if err != nil {
+		return DeadLinkDecision{}, err
+	}
+	if health == nil || health.Accessible || health.ConsecutiveFailures < s.deadLinks.FailureThreshold {
+		return DeadLinkDecision{Policy: models.DeadLinkKeep, Health: health}, nil
+	}
+
+	decision := DeadLinkDecision{Policy: policy, Health: health}
+	if policy == models.DeadLinkFallback {
+		decision.FallbackURL = link.FallbackURL
+		if decision.FallbackURL == "" {
+			decision.FallbackURL = s.deadLinks.FallbackURL
+		}
+		if decision.FallbackURL == "" {
+			// Aucune URL de repli configurée : on signale l'indisponibilité plutôt que d'envoyer vers une destination morte.
+			decision.Policy = models.DeadLinkUnavailable
+		}
+	}
+	return decision, nil
+}
+
+// GetLinkHealth retourne l'état courant d'un lien (nil s'il n'a pas encore été contrôlé)
+// ainsi que ses 'historyLimit' contrôles les plus récents.
+func (s *HealthService) GetLinkHealth(ctx context.Context, linkID uint, historyLimit int) (*models.LinkHealth, []models.HealthCheck, error) {
+	ctx, span := tracing.Start(ctx, "HealthService.GetLinkHealth")
+	defer span.End()
+
+	health, err := s.GetLinkStatus(ctx, linkID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, err := s.healthRepo.ListHealthChecks(ctx, linkID, historyLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return health, history, nil
+}
+
+// GetLinkStatus retourne l'état courant d'un lien, ou nil s'il n'a pas encore été contrôlé.
+func (s *HealthService) GetLinkStatus(ctx context.Context, linkID uint) (*models.LinkHealth, error) {
+	ctx, span := tracing.Start(ctx, "HealthService.GetLinkStatus")
+	defer span.End()
+
+	health, err := s.healthRepo.GetLinkHealth(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve link health: %w", err)
+	}
+	return health, nil
+}
+
+// ListBrokenLinks retourne les liens actifs dont la destination est actuellement inaccessible.
+func (s *HealthService) ListBrokenLinks(ctx context.Context) ([]models.LinkHealth, error) {
+	return s.healthRepo.ListBrokenLinks(ctx)
+}
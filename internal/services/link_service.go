@@ -1,103 +1,392 @@
 package services
 
 import (
-	"crypto/rand"
+	"context"
 	"errors"
 	"fmt"
-	"log"
-	"math/big"
+	"log/slog"
+	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/shortcode"
+	"github.com/axellelanca/urlshortener/internal/tracing"
+	"github.com/axellelanca/urlshortener/internal/urlguard"
 )
 
-const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// Contraintes appliquées aux alias personnalisés.
+const (
+	MinAliasLength = 3
+	MaxAliasLength = 32
+)
+
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// reservedAliases contient les chemins déjà utilisés par le routeur (ou qui pourraient l'être),
+// qui ne doivent donc jamais devenir des codes courts.
+var reservedAliases = map[string]struct{}{
+	"api":         {},
+	"health":      {},
+	"livez":       {},
+	"readyz":      {},
+	"admin":       {},
+	"report":      {},
+	"static":      {},
+	"favicon.ico": {},
+	"robots.txt":  {},
+}
+
+var (
+	// ErrInvalidAlias est retournée lorsque l'alias ne respecte pas le jeu de caractères ou la longueur autorisés.
+	ErrInvalidAlias = fmt.Errorf("alias must be %d to %d characters long and contain only letters, digits, '-' or '_'", MinAliasLength, MaxAliasLength)
+	// ErrReservedAlias est retournée lorsque l'alias entre en collision avec une route réservée.
+	ErrReservedAlias = errors.New("alias is reserved")
+	// ErrAliasTaken est retournée lorsque l'alias est déjà utilisé par un autre lien.
+	ErrAliasTaken = errors.New("alias is already taken")
+	// ErrInvalidExpiration est retournée lorsque la date d'expiration ou le quota de clics est incohérent.
+	ErrInvalidExpiration = errors.New("expires_at must be in the future and max_clicks must be positive")
+	// ErrInvalidSort est retournée lorsque le critère de tri demandé n'est pas supporté.
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrInvalidDeadLinkPolicy est retournée pour une politique de lien mort inconnue.
+	ErrInvalidDeadLinkPolicy = errors.New("dead_link_policy must be one of: keep, interstitial, fallback, unavailable")
+	// ErrDestinationNotAllowed est retournée (enveloppée avec le motif) lorsque l'URL de destination
+	// est refusée par la politique de sécurité : schéma, domaine interdit ou adresse interne.
+	ErrDestinationNotAllowed = urlguard.ErrNotAllowed
+	// ErrDestinationUnresolved est retournée lorsque la résolution DNS de la destination a échoué
+	// temporairement : la demande peut être réessayée.
+	ErrDestinationUnresolved = urlguard.ErrResolve
+	// ErrLinkBlocked est retournée lorsque la destination du lien a été bloquée par le moniteur.
+	ErrLinkBlocked = errors.New("link destination is blocked")
+	// ErrLinkDisabled est retournée lorsqu'un lien a été désactivé par un administrateur (signalement d'abus).
+	ErrLinkDisabled = errors.New("link has been disabled")
+	// ErrLinkExpired est retournée lorsqu'un lien a dépassé sa date d'expiration ou son quota de clics.
+	ErrLinkExpired = errors.New("link has expired")
+)
+
+// CreateLinkOptions regroupe les paramètres optionnels de création d'un lien.
+type CreateLinkOptions struct {
+	// Alias est un code court personnalisé. S'il est vide, un code aléatoire est généré.
+	Alias string
+	// ExpiresAt est la date d'expiration du lien (nil = jamais).
+	ExpiresAt *time.Time
+	// MaxClicks est le nombre maximal de clics autorisés (0 = illimité).
+	MaxClicks int
+	// OwnerID est la clé d'API propriétaire du lien (nil pour un lien créé en CLI).
+	OwnerID *uint
+	// DeadLinkPolicy est la politique appliquée lorsque la destination est hors service (vide = politique globale).
+	DeadLinkPolicy string
+	// FallbackURL est l'URL de repli de la politique "fallback" (vide = URL de repli globale).
+	FallbackURL string
+}
+
+// UpdateLinkOptions regroupe les champs modifiables d'un lien (nil = inchangé).
+type UpdateLinkOptions struct {
+	LongURL        *string
+	DeadLinkPolicy *string
+	FallbackURL    *string
+}
+
+// maxGenerationAttempts est le nombre de codes générés avant d'abandonner en cas de collisions répétées.
+const maxGenerationAttempts = 5
 
 type LinkService struct {
-	linkRepo repository.LinkRepository
+	linkRepo     repository.LinkRepository
+	generator    shortcode.Generator
+	destinations *urlguard.Policy
+	blocklist    *BlocklistService
 }
 
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
+func NewLinkService(linkRepo repository.LinkRepository, generator shortcode.Generator, destinations *urlguard.Policy, blocklist *BlocklistService) *LinkService {
 	return &LinkService{
-		linkRepo: linkRepo,
+		linkRepo:     linkRepo,
+		generator:    generator,
+		destinations: destinations,
+		blocklist:    blocklist,
 	}
 }
 
-func (s *LinkService) GenerateShortCode(length int) (string, error) {
-	code := make([]byte, length)
+// ValidateAlias vérifie qu'un alias personnalisé est syntaxiquement valide et n'est pas réservé.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength || !aliasPattern.MatchString(alias) {
+		return ErrInvalidAlias
+	}
+	if isReserved(alias) {
+		return ErrReservedAlias
+	}
+	return nil
+}
 
-	for i := range code {
-		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			return "", fmt.Errorf("failed to generate random short code: %w", err)
+// ValidateDeadLinkPolicy vérifie qu'une politique de lien mort est connue (vide = politique globale).
+func ValidateDeadLinkPolicy(policy string) error {
+	switch policy {
+	case "", models.DeadLinkKeep, models.DeadLinkInterstitial, models.DeadLinkFallback, models.DeadLinkUnavailable:
+		return nil
+	}
+	return ErrInvalidDeadLinkPolicy
+}
+
+// checkDestinations vérifie les URLs de destination non vides avec la politique de sécurité,
+// les règles de domaine et la liste de blocage.
+func (s *LinkService) checkDestinations(ctx context.Context, urls ...string) error {
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		if err := s.destinations.CheckURL(ctx, u); err != nil {
+			return err
 		}
-		code[i] = charset[randomIndex.Int64()]
 	}
+	return s.blocklist.Check(ctx, urls...)
+}
 
-	return string(code), nil
+// isReserved indique si un code entre en collision avec une route réservée.
+func isReserved(code string) bool {
+	_, reserved := reservedAliases[strings.ToLower(code)]
+	return reserved
 }
 
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
-	var shortCode string
-	const maxRetries = 5
+// CreateLink crée un lien court. L'unicité du code repose sur l'index unique de la base :
+// le lien est inséré directement et, en cas de collision, un nouveau code est généré.
+// Un alias déjà attribué est refusé avec ErrAliasTaken.
+func (s *LinkService) CreateLink(ctx context.Context, longURL string, opts CreateLinkOptions) (*models.Link, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.CreateLink")
+	defer span.End()
+
+	if opts.MaxClicks < 0 || (opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now())) {
+		return nil, ErrInvalidExpiration
+	}
+	if opts.Alias != "" {
+		if err := ValidateAlias(opts.Alias); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateDeadLinkPolicy(opts.DeadLinkPolicy); err != nil {
+		return nil, err
+	}
+	if err := s.checkDestinations(ctx, longURL, opts.FallbackURL); err != nil {
+		return nil, err
+	}
+
+	link := &models.Link{
+		LongURL:        longURL,
+		CreatedAt:      time.Now(),
+		MaxClicks:      opts.MaxClicks,
+		OwnerID:        opts.OwnerID,
+		DeadLinkPolicy: opts.DeadLinkPolicy,
+		FallbackURL:    opts.FallbackURL,
+	}
+	if opts.ExpiresAt != nil {
+		// Stockage en UTC pour que les comparaisons faites par le sweeper restent cohérentes.
+		expiresAt := opts.ExpiresAt.UTC()
+		link.ExpiresAt = &expiresAt
+	}
+
+	if opts.Alias != "" {
+		link.ShortCode = opts.Alias
+		err := s.linkRepo.CreateLink(ctx, link)
+		if errors.Is(err, repository.ErrDuplicateShortCode) {
+			return nil, ErrAliasTaken
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create link in database: %w", err)
+		}
+		metrics.LinksCreated.Inc()
+		return link, nil
+	}
 
-	for i := 0; i < maxRetries; i++ {
-		code, err := s.GenerateShortCode(6)
+	for i := 0; i < maxGenerationAttempts; i++ {
+		code, err := s.generator.Generate(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate short code: %w", err)
 		}
+		if isReserved(code) {
+			continue
+		}
 
-		_, err = s.linkRepo.GetLinkByShortCode(code)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code
-				break
-			}
-			return nil, fmt.Errorf("database error checking short code uniqueness: %w", err)
+		link.ShortCode = code
+		err = s.linkRepo.CreateLink(ctx, link)
+		if err == nil {
+			metrics.LinksCreated.Inc()
+			return link, nil
+		}
+		if !errors.Is(err, repository.ErrDuplicateShortCode) {
+			return nil, fmt.Errorf("failed to create link in database: %w", err)
 		}
 
-		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
+		slog.Debug("Short code already exists, retrying generation", "short_code", code, "attempt", i+1, "max_attempts", maxGenerationAttempts)
 	}
 
-	if shortCode == "" {
-		return nil, errors.New("failed to generate a unique short code after multiple attempts")
-	}
+	return nil, errors.New("failed to generate a unique short code after multiple attempts")
+}
 
-	link := &models.Link{
-		ShortCode: shortCode,
-		LongURL:   longURL,
-		CreatedAt: time.Now(),
+// ListLinks retourne la page 'page' (à partir de 1) des liens de 'ownerID', triée selon 'sort'.
+// 'sort' est un nom de colonne, éventuellement préfixé par '-' pour un tri décroissant (ex: "-created_at").
+func (s *LinkService) ListLinks(ctx context.Context, ownerID uint, page, pageSize int, sort string) ([]models.Link, int64, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.ListLinks")
+	defer span.End()
+
+	opts := repository.LinkListOptions{
+		OwnerID: &ownerID,
+		Offset:  (page - 1) * pageSize,
+		Limit:   pageSize,
+		SortBy:  strings.TrimPrefix(sort, "-"),
+		Desc:    strings.HasPrefix(sort, "-"),
+	}
+	if _, ok := repository.SortableLinkColumns[opts.SortBy]; !ok {
+		return nil, 0, ErrInvalidSort
 	}
 
-	if err := s.linkRepo.CreateLink(link); err != nil {
-		return nil, fmt.Errorf("failed to create link in database: %w", err)
+	links, total, err := s.linkRepo.ListLinks(ctx, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list links: %w", err)
 	}
+	return links, total, nil
+}
 
+// GetOwnedLink récupère un lien appartenant à la clé 'ownerID'.
+// Un lien appartenant à une autre clé est traité comme inexistant (gorm.ErrRecordNotFound)
+// afin de ne pas révéler son existence.
+func (s *LinkService) GetOwnedLink(ctx context.Context, shortCode string, ownerID uint) (*models.Link, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.GetOwnedLink")
+	defer span.End()
+
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if link.OwnerID == nil || *link.OwnerID != ownerID {
+		return nil, gorm.ErrRecordNotFound
+	}
 	return link, nil
 }
 
-func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+// UpdateLink modifie la destination et/ou la politique de lien mort d'un lien existant.
+// Le lien est relu en base (et non dans le cache) et seules les colonnes modifiées sont écrites ;
+// 'link' reçoit ensuite l'état à jour du lien.
+func (s *LinkService) UpdateLink(ctx context.Context, link *models.Link, opts UpdateLinkOptions) error {
+	ctx, span := tracing.Start(ctx, "LinkService.UpdateLink")
+	defer span.End()
+
+	current, err := s.linkRepo.GetLinkByID(ctx, link.ID)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{})
+	if opts.DeadLinkPolicy != nil {
+		if err := ValidateDeadLinkPolicy(*opts.DeadLinkPolicy); err != nil {
+			return err
+		}
+		current.DeadLinkPolicy = *opts.DeadLinkPolicy
+		fields["dead_link_policy"] = current.DeadLinkPolicy
+	}
+	if opts.LongURL != nil {
+		if err := s.checkDestinations(ctx, *opts.LongURL); err != nil {
+			return err
+		}
+		current.LongURL = *opts.LongURL
+		fields["long_url"] = current.LongURL
+	}
+	if opts.FallbackURL != nil {
+		if err := s.checkDestinations(ctx, *opts.FallbackURL); err != nil {
+			return err
+		}
+		current.FallbackURL = *opts.FallbackURL
+		fields["fallback_url"] = current.FallbackURL
+	}
+	if current.BlockedAt != nil && (opts.LongURL != nil || opts.FallbackURL != nil) {
+		// Nouvelle destination : le blocage est levé si plus aucune URL du lien n'est bloquée.
+		if err := s.blocklist.Check(ctx, current.LongURL, current.FallbackURL); err == nil {
+			current.BlockedAt = nil
+			current.BlockedReason = ""
+			fields["blocked_at"] = nil
+			fields["blocked_reason"] = ""
+		}
+	}
+	if len(fields) > 0 {
+		if err := s.linkRepo.UpdateLink(ctx, current, fields); err != nil {
+			return fmt.Errorf("failed to update link: %w", err)
+		}
+	}
+	*link = *current
+	return nil
+}
+
+// DeleteLink supprime logiquement un lien. Son code court ne sera jamais réattribué.
+func (s *LinkService) DeleteLink(ctx context.Context, link *models.Link) error {
+	ctx, span := tracing.Start(ctx, "LinkService.DeleteLink")
+	defer span.End()
+
+	if err := s.linkRepo.DeleteLink(ctx, link); err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+	return nil
+}
+
+// ResolveLink récupère le lien à rediriger et vérifie qu'il n'est ni désactivé, ni bloqué, ni expiré.
+// Dans ces cas, le lien est retourné accompagné de ErrLinkDisabled, ErrLinkBlocked ou ErrLinkExpired.
+func (s *LinkService) ResolveLink(ctx context.Context, shortCode string) (*models.Link, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.ResolveLink")
+	defer span.End()
+
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
 	if err != nil {
 		return nil, err
 	}
+	if link.DisabledAt != nil {
+		return link, ErrLinkDisabled
+	}
+	if link.BlockedAt != nil {
+		return link, ErrLinkBlocked
+	}
+
+	// Le comptage des clics n'est nécessaire que si le lien est limité en nombre de clics.
+	// Les clics étant enregistrés de manière asynchrone, le quota peut être légèrement dépassé.
+	totalClicks := 0
+	if link.MaxClicks > 0 && link.ExpiredAt == nil {
+		totalClicks, err = s.linkRepo.CountClicksByLinkID(ctx, link.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count clicks: %w", err)
+		}
+	}
+
+	if link.IsExpired(time.Now(), totalClicks) {
+		return link, ErrLinkExpired
+	}
 	return link, nil
 }
 
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+// ExpireLinks marque comme expirés tous les liens arrivés en fin de vie.
+// Cette méthode est appelée périodiquement par le sweeper.
+func (s *LinkService) ExpireLinks(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.ExpireLinks")
+	defer span.End()
+
+	count, err := s.linkRepo.MarkExpiredLinks(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire links: %w", err)
+	}
+	return count, nil
+}
+
+func (s *LinkService) GetLinkStats(ctx context.Context, shortCode string) (*models.Link, int, error) {
+	ctx, span := tracing.Start(ctx, "LinkService.GetLinkStats")
+	defer span.End()
+
+	link, err := s.linkRepo.GetLinkByShortCode(ctx, shortCode)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	totalClicks, err := s.linkRepo.CountClicksByLinkID(link.ID)
+	totalClicks, err := s.linkRepo.CountClicksByLinkID(ctx, link.ID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count clicks: %w", err)
 	}
 
 	return link, totalClicks, nil
 }
-
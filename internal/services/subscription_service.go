@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/notifier"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/urlguard"
+)
+
+// MaxSubscriptionsPerLink borne le nombre de destinataires notifiés pour un même lien.
+const MaxSubscriptionsPerLink = 10
+
+var (
+	// ErrInvalidChannel est retournée pour un canal de notification inconnu.
+	ErrInvalidChannel = errors.New("channel must be one of: webhook, slack, email")
+	// ErrInvalidTarget est retournée lorsque la cible ne correspond pas au canal (URL http(s) ou adresse e-mail).
+	ErrInvalidTarget = errors.New("target must be an http(s) URL for webhook and slack, or an email address for email")
+	// ErrEmailUnavailable est retournée pour un abonnement e-mail lorsque SMTP n'est pas configuré.
+	ErrEmailUnavailable = errors.New("email notifications are not configured on this server")
+	// ErrTooManySubscriptions est retournée lorsque le lien a atteint MaxSubscriptionsPerLink.
+	ErrTooManySubscriptions = errors.New("too many subscriptions for this link")
+)
+
+// SubscriptionService gère les abonnements aux notifications du moniteur pour chaque lien.
+type SubscriptionService struct {
+	subRepo      repository.SubscriptionRepository
+	emailEnabled bool
+	destinations *urlguard.Policy
+}
+
+// NewSubscriptionService crée et retourne une nouvelle instance de SubscriptionService.
+// 'emailEnabled' indique si un serveur SMTP est configuré pour les abonnements e-mail ;
+// 'destinations' refuse les webhooks vers le réseau interne.
+func NewSubscriptionService(subRepo repository.SubscriptionRepository, emailEnabled bool, destinations *urlguard.Policy) *SubscriptionService {
+	return &SubscriptionService{
+		subRepo:      subRepo,
+		emailEnabled: emailEnabled,
+		destinations: destinations,
+	}
+}
+
+// CreateSubscription abonne 'target' aux changements d'état du lien via 'channel'.
+// 'secret' n'est utilisé que par les webhooks JSON, pour signer les requêtes.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, linkID uint, channel, target, secret string) (*models.LinkSubscription, error) {
+	switch channel {
+	case notifier.ChannelWebhook, notifier.ChannelSlack:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ErrInvalidTarget
+		}
+		if err := s.destinations.CheckURL(ctx, target); err != nil {
+			return nil, err
+		}
+	case notifier.ChannelEmail:
+		if !s.emailEnabled {
+			return nil, ErrEmailUnavailable
+		}
+		addr, err := mail.ParseAddress(target)
+		if err != nil {
+			return nil, ErrInvalidTarget
+		}
+		target = addr.Address
+	default:
+		return nil, ErrInvalidChannel
+	}
+	if channel != notifier.ChannelWebhook {
+		secret = ""
+	}
+
+	existing, err := s.subRepo.ListSubscriptionsByLink(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxSubscriptionsPerLink {
+		return nil, ErrTooManySubscriptions
+	}
+
+	sub := &models.LinkSubscription{
+		LinkID:  linkID,
+		Channel: channel,
+		Target:  target,
+		Secret:  secret,
+	}
+	if err := s.subRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions retourne les abonnements d'un lien.
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, linkID uint) ([]models.LinkSubscription, error) {
+	return s.subRepo.ListSubscriptionsByLink(ctx, linkID)
+}
+
+// DeleteSubscription supprime un abonnement du lien.
+// Retourne gorm.ErrRecordNotFound si l'abonnement n'existe pas pour ce lien.
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id, linkID uint) error {
+	return s.subRepo.DeleteSubscription(ctx, id, linkID)
+}
@@ -0,0 +1,137 @@
+package shortcode
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Stratégies de génération disponibles (clé 'shortcode.strategy' de la configuration).
+const (
+	StrategyRandom        = "random"
+	StrategySequential    = "sequential"
+	StrategyHashids       = "hashids"
+	StrategyPronounceable = "pronounceable"
+)
+
+// DefaultAlphabet est l'alphabet base62 utilisé par défaut.
+const DefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Generator produit des codes courts candidats. L'unicité n'est pas garantie par le générateur :
+// elle est assurée par l'index unique de la base, l'appelant réessayant en cas de collision.
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Counter fournit des entiers strictement croissants et partagés entre les instances du service
+// (typiquement une séquence stockée en base). Il alimente les stratégies séquentielle et hashids.
+type Counter interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// CounterFunc permet d'utiliser une simple fonction comme Counter.
+type CounterFunc func(ctx context.Context) (uint64, error)
+
+func (f CounterFunc) Next(ctx context.Context) (uint64, error) { return f(ctx) }
+
+// Options regroupe les paramètres de toutes les stratégies ; seuls ceux de la stratégie choisie sont utilisés.
+type Options struct {
+	Strategy string
+	// Length est la longueur des codes aléatoires.
+	Length int
+	// Alphabet est le jeu de caractères des stratégies random, sequential et hashids.
+	Alphabet string
+	// MinLength est la longueur minimale des codes séquentiels et hashids.
+	MinLength int
+	// Salt personnalise le mélange de l'alphabet de la stratégie hashids.
+	Salt string
+	// Syllables est le nombre de syllabes (consonne + voyelle) des codes prononçables.
+	Syllables int
+}
+
+// New construit le générateur correspondant à 'opts.Strategy'.
+// 'counter' n'est requis que pour les stratégies sequential et hashids.
+func New(opts Options, counter Counter) (Generator, error) {
+	alphabet := opts.Alphabet
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	switch opts.Strategy {
+	case StrategyRandom, "":
+		if opts.Length <= 0 {
+			return nil, fmt.Errorf("shortcode: length must be positive")
+		}
+		return &RandomGenerator{Length: opts.Length, Alphabet: alphabet}, nil
+	case StrategySequential:
+		if counter == nil {
+			return nil, fmt.Errorf("shortcode: strategy %q requires a counter", opts.Strategy)
+		}
+		return &SequentialGenerator{Counter: counter, Alphabet: alphabet, MinLength: opts.MinLength}, nil
+	case StrategyHashids:
+		if counter == nil {
+			return nil, fmt.Errorf("shortcode: strategy %q requires a counter", opts.Strategy)
+		}
+		return NewHashidsGenerator(counter, alphabet, opts.Salt, opts.MinLength), nil
+	case StrategyPronounceable:
+		if opts.Syllables <= 0 {
+			return nil, fmt.Errorf("shortcode: syllables must be positive")
+		}
+		return &PronounceableGenerator{Syllables: opts.Syllables}, nil
+	}
+	return nil, fmt.Errorf("shortcode: unknown strategy %q", opts.Strategy)
+}
+
+// validateAlphabet vérifie que l'alphabet contient au moins deux caractères ASCII distincts.
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return fmt.Errorf("shortcode: alphabet must contain at least 2 characters")
+	}
+	seen := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		if r > 127 || seen[r] {
+			return fmt.Errorf("shortcode: alphabet must contain distinct ASCII characters")
+		}
+		seen[r] = true
+	}
+	return nil
+}
+
+// randomIndex retourne un entier aléatoire (source cryptographique) dans [0, n).
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random source: %w", err)
+	}
+	return int(i.Int64()), nil
+}
+
+// encode écrit 'n' en base len(alphabet), en complétant à 'minLength' caractères.
+// Le complément est obtenu en décalant 'n' de base^(minLength-1) plutôt qu'en ajoutant des caractères
+// de remplissage, ce qui garde l'encodage injectif. Le calcul est fait en précision arbitraire :
+// le décalage dépasse uint64 dès 12 caractères en base62.
+func encode(n uint64, alphabet string, minLength int) string {
+	base := big.NewInt(int64(len(alphabet)))
+	value := new(big.Int).SetUint64(n)
+	if minLength > 1 {
+		value.Add(value, new(big.Int).Exp(base, big.NewInt(int64(minLength-1)), nil))
+	}
+
+	if value.Sign() == 0 {
+		return alphabet[:1]
+	}
+	var buf []byte
+	digit := new(big.Int)
+	for value.Sign() > 0 {
+		value.DivMod(value, base, digit)
+		buf = append(buf, alphabet[digit.Int64()])
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
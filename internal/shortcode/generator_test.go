@@ -0,0 +1,232 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// decode est l'inverse de encode : il retrouve la valeur encodée (en précision arbitraire,
+// le décalage de complément pouvant dépasser uint64).
+func decode(t *testing.T, code, alphabet string, minLength int) *big.Int {
+	t.Helper()
+	base := big.NewInt(int64(len(alphabet)))
+	value := new(big.Int)
+	for _, c := range code {
+		digit := strings.IndexRune(alphabet, c)
+		if digit < 0 {
+			t.Fatalf("code %q contains %q, which is not in the alphabet", code, c)
+		}
+		value.Mul(value, base).Add(value, big.NewInt(int64(digit)))
+	}
+	if minLength > 1 {
+		value.Sub(value, new(big.Int).Exp(base, big.NewInt(int64(minLength-1)), nil))
+	}
+	return value
+}
+
+// sequence retourne un Counter renvoyant start, start+1, start+2...
+func sequence(start uint64) Counter {
+	next := start
+	return CounterFunc(func(context.Context) (uint64, error) {
+		n := next
+		next++
+		return n, nil
+	})
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		n         uint64
+		alphabet  string
+		minLength int
+		want      string
+	}{
+		{0, DefaultAlphabet, 0, "a"},
+		{61, DefaultAlphabet, 0, "9"},
+		{62, DefaultAlphabet, 0, "ba"},
+		{0, DefaultAlphabet, 3, "baa"},
+		{1, DefaultAlphabet, 3, "bab"},
+		{5, "01", 0, "101"},
+		{0, "01", 4, "1000"},
+		{math.MaxUint64, "0123456789abcdef", 0, "ffffffffffffffff"},
+	}
+	for _, tt := range tests {
+		if got := encode(tt.n, tt.alphabet, tt.minLength); got != tt.want {
+			t.Errorf("encode(%d, %q, %d) = %q, want %q", tt.n, tt.alphabet, tt.minLength, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	values := []uint64{0, 1, 61, 62, 3843, 3844, 1 << 32, math.MaxUint64 - 1, math.MaxUint64}
+	// Au-delà de 11 caractères en base62, le décalage de complément dépasse uint64.
+	for _, minLength := range []int{0, 1, 2, 6, 11, 12, 20, 32} {
+		seen := make(map[string]uint64, len(values))
+		for _, n := range values {
+			code := encode(n, DefaultAlphabet, minLength)
+			if len(code) < minLength {
+				t.Errorf("encode(%d, minLength %d) = %q, shorter than minLength", n, minLength, code)
+			}
+			if got := decode(t, code, DefaultAlphabet, minLength); !got.IsUint64() || got.Uint64() != n {
+				t.Errorf("decode(encode(%d, minLength %d) = %q) = %s", n, minLength, code, got)
+			}
+			if previous, ok := seen[code]; ok {
+				t.Errorf("encode(%d) and encode(%d) both give %q with minLength %d", previous, n, code, minLength)
+			}
+			seen[code] = n
+		}
+	}
+}
+
+func TestSequentialGenerator(t *testing.T) {
+	g := &SequentialGenerator{Counter: sequence(0), Alphabet: DefaultAlphabet, MinLength: 4}
+	seen := make(map[string]bool)
+	for i := uint64(0); i < 10000; i++ {
+		code, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if len(code) < 4 {
+			t.Errorf("code %q is shorter than MinLength", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q", code)
+		}
+		seen[code] = true
+		if got := decode(t, code, DefaultAlphabet, 4); got.Uint64() != i {
+			t.Errorf("code %q decodes to %s, want %d", code, got, i)
+		}
+	}
+}
+
+func TestHashidsGenerator(t *testing.T) {
+	tests := []struct {
+		name      string
+		alphabet  string
+		salt      string
+		minLength int
+		start     uint64
+	}{
+		{"default alphabet", DefaultAlphabet, "sel", 6, 0},
+		{"no salt", DefaultAlphabet, "", 6, 0},
+		{"short codes", DefaultAlphabet, "sel", 0, 0},
+		{"large min_length", DefaultAlphabet, "sel", 32, 0},
+		{"large counter", DefaultAlphabet, "sel", 6, math.MaxUint64 - 1000},
+		{"small alphabet", "abcdef", "sel", 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHashidsGenerator(sequence(tt.start), tt.alphabet, tt.salt, tt.minLength)
+			seen := make(map[string]bool)
+			for i := 0; i < 1000; i++ {
+				n := tt.start + uint64(i)
+				code, err := g.Generate(context.Background())
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if len(code) < tt.minLength {
+					t.Errorf("code %q is shorter than minLength %d", code, tt.minLength)
+				}
+				if seen[code] {
+					t.Fatalf("duplicate code %q", code)
+				}
+				seen[code] = true
+
+				// Le premier caractère choisit le mélange de l'alphabet qui encode le reste du code.
+				alphabet := consistentShuffle(tt.alphabet, tt.salt)
+				shuffled := consistentShuffle(alphabet, code[:1]+tt.salt)
+				if got := decode(t, code[1:], shuffled, tt.minLength-1); !got.IsUint64() || got.Uint64() != n {
+					t.Errorf("code %q decodes to %s, want %d", code, got, n)
+				}
+			}
+		})
+	}
+}
+
+func TestHashidsGeneratorSalt(t *testing.T) {
+	first, _ := NewHashidsGenerator(sequence(42), DefaultAlphabet, "un", 6).Generate(context.Background())
+	again, _ := NewHashidsGenerator(sequence(42), DefaultAlphabet, "un", 6).Generate(context.Background())
+	other, _ := NewHashidsGenerator(sequence(42), DefaultAlphabet, "deux", 6).Generate(context.Background())
+	if first != again {
+		t.Errorf("same salt gives %q then %q, want deterministic codes", first, again)
+	}
+	if first == other {
+		t.Errorf("different salts both give %q", first)
+	}
+}
+
+func TestRandomAndPronounceableGenerators(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator Generator
+		length    int
+		alphabet  string
+	}{
+		{"random", &RandomGenerator{Length: 8, Alphabet: DefaultAlphabet}, 8, DefaultAlphabet},
+		{"random small alphabet", &RandomGenerator{Length: 12, Alphabet: "ab"}, 12, "ab"},
+		{"pronounceable", &PronounceableGenerator{Syllables: 3}, 6, consonants + vowels},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				code, err := tt.generator.Generate(context.Background())
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if len(code) != tt.length {
+					t.Errorf("code %q has length %d, want %d", code, len(code), tt.length)
+				}
+				if strings.Trim(code, tt.alphabet) != "" {
+					t.Errorf("code %q uses characters outside %q", code, tt.alphabet)
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	counter := sequence(0)
+	tests := []struct {
+		name    string
+		opts    Options
+		counter Counter
+		wantErr bool
+	}{
+		{"random by default", Options{Length: 6}, nil, false},
+		{"random without length", Options{Strategy: StrategyRandom}, nil, true},
+		{"sequential", Options{Strategy: StrategySequential}, counter, false},
+		{"sequential without counter", Options{Strategy: StrategySequential}, nil, true},
+		{"hashids", Options{Strategy: StrategyHashids, MinLength: 6}, counter, false},
+		{"hashids without counter", Options{Strategy: StrategyHashids}, nil, true},
+		{"pronounceable", Options{Strategy: StrategyPronounceable, Syllables: 3}, nil, false},
+		{"pronounceable without syllables", Options{Strategy: StrategyPronounceable}, nil, true},
+		{"unknown strategy", Options{Strategy: "uuid"}, nil, true},
+		{"one-character alphabet", Options{Length: 6, Alphabet: "a"}, nil, true},
+		{"duplicate characters", Options{Length: 6, Alphabet: "abca"}, nil, true},
+		{"non-ASCII alphabet", Options{Length: 6, Alphabet: "abcé"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts, tt.counter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(%+v) error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratorCounterError(t *testing.T) {
+	failing := CounterFunc(func(context.Context) (uint64, error) { return 0, errors.New("sequence unavailable") })
+	for _, g := range []Generator{
+		&SequentialGenerator{Counter: failing, Alphabet: DefaultAlphabet},
+		NewHashidsGenerator(failing, DefaultAlphabet, "sel", 6),
+	} {
+		if _, err := g.Generate(context.Background()); err == nil {
+			t.Errorf("%T.Generate(context.Background()) succeeded with a failing counter", g)
+		}
+	}
+}
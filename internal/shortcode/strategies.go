@@ -0,0 +1,118 @@
+package shortcode
+
+import (
+	"context"
+	"fmt"
+)
+
+// RandomGenerator produit des codes aléatoires de longueur fixe.
+type RandomGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+func (g *RandomGenerator) Generate(context.Context) (string, error) {
+	code := make([]byte, g.Length)
+	for i := range code {
+		idx, err := randomIndex(len(g.Alphabet))
+		if err != nil {
+			return "", err
+		}
+		code[i] = g.Alphabet[idx]
+	}
+	return string(code), nil
+}
+
+// SequentialGenerator encode la valeur suivante d'un compteur dans l'alphabet (base62 par défaut).
+// Les codes sont les plus courts possibles mais prévisibles.
+type SequentialGenerator struct {
+	Counter   Counter
+	Alphabet  string
+	MinLength int
+}
+
+func (g *SequentialGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.Counter.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next sequence value: %w", err)
+	}
+	return encode(n, g.Alphabet, g.MinLength), nil
+}
+
+// HashidsGenerator obfusque un compteur à la manière de hashids : le premier caractère (« loterie »)
+// est dérivé de la valeur, et l'alphabet utilisé pour le reste du code est mélangé à partir de ce
+// caractère et d'un sel. Deux valeurs consécutives donnent ainsi des codes sans lien apparent,
+// tout en restant uniques puisque l'encodage est réversible.
+type HashidsGenerator struct {
+	counter   Counter
+	alphabet  string
+	salt      string
+	minLength int
+}
+
+// NewHashidsGenerator crée un HashidsGenerator. L'alphabet est mélangé une première fois avec le sel,
+// de sorte que deux déploiements avec des sels différents produisent des codes différents.
+func NewHashidsGenerator(counter Counter, alphabet, salt string, minLength int) *HashidsGenerator {
+	return &HashidsGenerator{
+		counter:   counter,
+		alphabet:  consistentShuffle(alphabet, salt),
+		salt:      salt,
+		minLength: minLength,
+	}
+}
+
+func (g *HashidsGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.counter.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next sequence value: %w", err)
+	}
+
+	lottery := g.alphabet[n%uint64(len(g.alphabet))]
+	shuffled := consistentShuffle(g.alphabet, string(lottery)+g.salt)
+	return string(lottery) + encode(n, shuffled, g.minLength-1), nil
+}
+
+// consistentShuffle mélange 'alphabet' de façon déterministe à partir de 'salt'
+// (même algorithme que la bibliothèque hashids).
+func consistentShuffle(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+	result := []byte(alphabet)
+	for i, v, p := len(result)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		integer := int(salt[v])
+		p += integer
+		j := (integer + v + p) % i
+		result[i], result[j] = result[j], result[i]
+		v++
+	}
+	return string(result)
+}
+
+const (
+	consonants = "bcdfghjklmnprstvz"
+	vowels     = "aeiou"
+)
+
+// PronounceableGenerator produit des codes faciles à lire et à dicter, formés de syllabes
+// consonne + voyelle (ex: "bakotu").
+type PronounceableGenerator struct {
+	Syllables int
+}
+
+func (g *PronounceableGenerator) Generate(context.Context) (string, error) {
+	code := make([]byte, 0, 2*g.Syllables)
+	for i := 0; i < g.Syllables; i++ {
+		c, err := randomIndex(len(consonants))
+		if err != nil {
+			return "", err
+		}
+		v, err := randomIndex(len(vowels))
+		if err != nil {
+			return "", err
+		}
+		code = append(code, consonants[c], vowels[v])
+	}
+	return string(code), nil
+}
@@ -0,0 +1,14 @@
+package textutil
+
+import "unicode/utf8"
+
+// Truncate tronque 's' à 'max' octets, sans couper un caractère UTF-8 (que PostgreSQL refuserait).
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,32 @@
+package textutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abc", 3, "abc"},
+		{"abcdef", 3, "abc"},
+		{"abc", 0, ""},
+		{"café", 4, "caf"},
+		{"café", 5, "café"},
+		{"日本語", 5, "日"},
+		{"日本語", 2, ""},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.max)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) = %q, not valid UTF-8", tt.s, tt.max, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package urlguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNotAllowed est l'erreur de base de toutes les destinations refusées.
+var ErrNotAllowed = errors.New("destination not allowed")
+
+// ErrResolve est retournée lorsque la résolution DNS de l'hôte échoue sans que le domaine soit inexistant
+// (délai dépassé, serveur DNS en erreur) : la destination n'est pas refusée, le contrôle peut être réessayé.
+var ErrResolve = errors.New("cannot resolve destination host")
+
+// resolveTimeout borne la résolution DNS d'une destination.
+const resolveTimeout = 5 * time.Second
+
+// blockedPrefixes complète les plages reconnues par netip.Addr (loopback, privées dont fd00:ec2::254,
+// lien local dont 169.254.169.254, multicast) avec les plages réservées qui ne doivent jamais être jointes.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // « Ce réseau »
+	netip.MustParsePrefix("100.64.0.0/10"),  // NAT des opérateurs (inclut 100.100.100.200, métadonnées Alibaba)
+	netip.MustParsePrefix("192.0.0.0/24"),   // Affectations IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // Tests de performance
+	netip.MustParsePrefix("240.0.0.0/4"),    // Réservé (inclut la diffusion 255.255.255.255)
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 : traduit vers une adresse IPv4 arbitraire
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 local
+	netip.MustParsePrefix("2002::/16"),      // 6to4 : encapsule une adresse IPv4 arbitraire
+	netip.MustParsePrefix("2001::/32"),      // Teredo : encapsule une adresse IPv4 arbitraire
+	netip.MustParsePrefix("100::/64"),       // Adresses de rejet
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+}
+
+// Policy protège le service contre les requêtes forgées côté serveur (SSRF) : elle refuse les destinations
+// qui pointent vers le réseau interne (loopback, réseaux privés, lien local, services de métadonnées cloud),
+// les schémas autres que http(s) et les domaines interdits par la configuration.
+type Policy struct {
+	allowPrivate  bool
+	deniedDomains []string
+	resolver      *net.Resolver
+}
+
+// New crée une politique. 'allowPrivate' désactive le contrôle des adresses (développement local uniquement) ;
+// 'deniedDomains' interdit ces domaines et tous leurs sous-domaines.
+func New(allowPrivate bool, deniedDomains []string) *Policy {
+	normalized := make([]string, 0, len(deniedDomains))
+	for _, domain := range deniedDomains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return &Policy{
+		allowPrivate:  allowPrivate,
+		deniedDomains: normalized,
+		resolver:      net.DefaultResolver,
+	}
+}
+
+// CheckURL vérifie le schéma, le domaine et les adresses IP (après résolution DNS) d'une URL.
+// La résolution peut changer entre ce contrôle et une connexion ultérieure : les clients HTTP
+// doivent aussi utiliser Control pour vérifier l'adresse effectivement contactée.
+func (p *Policy) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := p.CheckStatic(rawURL)
+	if err != nil {
+		return err
+	}
+	if p.allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%w: host %q does not exist", ErrNotAllowed, host)
+		}
+		return fmt.Errorf("%w %q: %v", ErrResolve, host, err)
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(addr); err != nil {
+			return fmt.Errorf("%w (resolved from %q)", err, host)
+		}
+	}
+	return nil
+}
+
+// CheckStatic vérifie une URL sans résolution DNS : schéma http(s), hôte présent et domaine autorisé.
+func (p *Policy) CheckStatic(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid URL", ErrNotAllowed)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: scheme %q is not allowed, use http or https", ErrNotAllowed, u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrNotAllowed)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials in URL are not allowed", ErrNotAllowed)
+	}
+	for _, denied := range p.deniedDomains {
+		if host == denied || strings.HasSuffix(host, "."+denied) {
+			return nil, fmt.Errorf("%w: domain %q is denied", ErrNotAllowed, host)
+		}
+	}
+	return u, nil
+}
+
+// Control est destiné au champ Control d'un net.Dialer : il refuse la connexion si l'adresse
+// réellement contactée (après résolution, redirections comprises) est interne.
+func (p *Policy) Control(network, address string, _ syscall.RawConn) error {
+	if p.allowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected address %q", ErrNotAllowed, address)
+	}
+	return p.checkAddr(addrPort.Addr())
+}
+
+// checkAddr refuse les adresses internes, réservées ou de métadonnées.
+func (p *Policy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("%w: address %s is internal", ErrNotAllowed, addr)
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: address %s is in reserved range %s", ErrNotAllowed, addr, prefix)
+		}
+	}
+	return nil
+}
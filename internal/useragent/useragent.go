@@ -0,0 +1,108 @@
+package useragent
+
+import "strings"
+
+// Classes d'appareils reconnues.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	Unknown       = "unknown"
+)
+
+// Info contient les informations extraites d'une chaîne User-Agent.
+type Info struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// signature associe un fragment de User-Agent à un nom lisible.
+// L'ordre des listes est significatif : par exemple Edge et Opera s'annoncent aussi comme Chrome,
+// et Chrome s'annonce aussi comme Safari.
+type signature struct {
+	token string
+	name  string
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client", "headless", "facebookexternalhit"}
+
+var browsers = []signature{
+	{"edg/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"yabrowser/", "Yandex"},
+	{"vivaldi/", "Vivaldi"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chromium/", "Chromium"},
+	{"chrome/", "Chrome"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"safari/", "Safari"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+}
+
+var operatingSystems = []signature{
+	{"windows phone", "Windows Phone"},
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"cros", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+// Parse interprète une chaîne User-Agent et en déduit le navigateur, le système et la classe d'appareil.
+// Les valeurs non reconnues valent Unknown.
+func Parse(ua string) Info {
+	s := strings.ToLower(ua)
+	if strings.TrimSpace(s) == "" {
+		return Info{Browser: Unknown, OS: Unknown, Device: Unknown}
+	}
+
+	return Info{
+		Browser: match(s, browsers),
+		OS:      match(s, operatingSystems),
+		Device:  device(s),
+	}
+}
+
+// match retourne le nom de la première signature trouvée dans 's'.
+func match(s string, signatures []signature) string {
+	for _, sig := range signatures {
+		if strings.Contains(s, sig.token) {
+			return sig.name
+		}
+	}
+	return Unknown
+}
+
+// device déduit la classe d'appareil à partir des marqueurs usuels des User-Agents.
+func device(s string) string {
+	for _, token := range botTokens {
+		if strings.Contains(s, token) {
+			return DeviceBot
+		}
+	}
+	switch {
+	case strings.Contains(s, "ipad"), strings.Contains(s, "tablet"),
+		strings.Contains(s, "android") && !strings.Contains(s, "mobile"):
+		return DeviceTablet
+	case strings.Contains(s, "mobi"), strings.Contains(s, "iphone"), strings.Contains(s, "ipod"),
+		strings.Contains(s, "windows phone"):
+		return DeviceMobile
+	case strings.Contains(s, "windows"), strings.Contains(s, "macintosh"), strings.Contains(s, "x11"),
+		strings.Contains(s, "cros"), strings.Contains(s, "linux"):
+		return DeviceDesktop
+	}
+	return Unknown
+}
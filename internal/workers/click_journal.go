@@ -0,0 +1,270 @@
+package workers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// ClickJournal est un journal local (un événement JSON par ligne) dans lequel sont déversés
+// les événements de clic qui n'ont pas pu être traités : channel saturé ou écriture en base
+// en échec après tous les essais. Il est rejoué au démarrage suivant pour qu'aucun clic ne soit perdu.
+type ClickJournal struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+
+	// Les clics refusés par le channel saturé sont écrits en arrière-plan, par lots (une seule synchronisation
+	// disque par lot) : la redirection n'attend jamais le disque.
+	overflow     chan models.ClickEvent
+	overflowDone chan struct{}
+	closeMu      sync.RWMutex // Protège l'envoi dans 'overflow' contre sa fermeture
+	closed       bool
+}
+
+// journalEntry est une ligne du journal : l'événement et le nombre de rejeux où il n'a pas pu être écrit.
+// Les lignes écrites par Append ne portent pas le compteur (0).
+type journalEntry struct {
+	models.ClickEvent
+	Attempts int `json:"replay_attempts,omitempty"`
+}
+
+// OpenClickJournal ouvre (ou crée) le journal situé à 'path' en mode ajout. Jusqu'à 'queueSize' clics
+// passés à Enqueue peuvent attendre leur écriture.
+func OpenClickJournal(path string, queueSize int) (*ClickJournal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open click journal %s: %w", path, err)
+	}
+	j := &ClickJournal{
+		path:         path,
+		file:         file,
+		overflow:     make(chan models.ClickEvent, queueSize),
+		overflowDone: make(chan struct{}),
+	}
+	go j.writeOverflow()
+	return j, nil
+}
+
+// Enqueue confie un clic à l'écriture en arrière-plan, sans bloquer. Retourne false si la file
+// d'écriture est pleine ou le journal fermé : le clic est alors perdu.
+func (j *ClickJournal) Enqueue(event models.ClickEvent) bool {
+	j.closeMu.RLock()
+	defer j.closeMu.RUnlock()
+	if j.closed {
+		return false
+	}
+	select {
+	case j.overflow <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeOverflow écrit les clics confiés à Enqueue jusqu'à la fermeture du journal, en regroupant
+// ceux qui attendent déjà.
+func (j *ClickJournal) writeOverflow() {
+	defer close(j.overflowDone)
+	for event := range j.overflow {
+		events := []models.ClickEvent{event}
+	drain:
+		for len(events) < cap(j.overflow) {
+			select {
+			case next, ok := <-j.overflow:
+				if !ok {
+					break drain
+				}
+				events = append(events, next)
+			default:
+				break drain
+			}
+		}
+		if err := j.Append(events...); err != nil {
+			slog.Error("Failed to write overflow clicks to journal, events lost",
+				"clicks", len(events), "request_ids", requestIDs(events), "error", err)
+		}
+	}
+}
+
+// Append ajoute des événements à la fin du journal et force leur écriture sur disque.
+func (j *ClickJournal) Append(events ...models.ClickEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	w := bufio.NewWriter(j.file)
+	enc := json.NewEncoder(w)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode click event: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write click journal: %w", err)
+	}
+	return j.file.Sync()
+}
+
+// Replay relit le journal par lots de 'batchSize' événements et passe chaque lot à 'persist'.
+// Les événements persistés sont retirés du journal. Un lot en échec est réessayé événement par événement,
+// pour que quelques événements impossibles à écrire ne bloquent pas les autres : ceux qui échouent sont
+// conservés pour un prochain rejeu, puis déplacés dans le fichier de quarantaine ('path'.rejected) après
+// 'maxAttempts' rejeux. Si aucun événement ne peut être écrit (base indisponible), le rejeu s'arrête au premier
+// lot. Retourne le nombre d'événements rejoués.
+func (j *ClickJournal) Replay(batchSize, maxAttempts int, persist func([]models.ClickEvent) error) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.readAll()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	var kept, rejected []journalEntry
+	var replayErr error
+	for start := 0; start < len(entries); start += batchSize {
+		end := min(start+batchSize, len(entries))
+		batch := entries[start:end]
+		if err := persist(clickEvents(batch)); err == nil {
+			replayed += len(batch)
+			continue
+		}
+
+		failed := 0
+		for _, entry := range batch {
+			if err := persist([]models.ClickEvent{entry.ClickEvent}); err != nil {
+				replayErr = err
+				failed++
+				entry.Attempts++
+				if entry.Attempts >= maxAttempts {
+					rejected = append(rejected, entry)
+				} else {
+					kept = append(kept, entry)
+				}
+				continue
+			}
+			replayed++
+		}
+		if failed == len(batch) && replayed == 0 {
+			// Rien n'a pu être écrit : la base est vraisemblablement indisponible, inutile d'essayer les lots suivants.
+			kept = append(kept, entries[end:]...)
+			break
+		}
+	}
+
+	if len(rejected) > 0 {
+		if err := appendEntries(j.path+".rejected", rejected); err != nil {
+			// Les événements restent dans le journal plutôt que d'être perdus.
+			kept = append(kept, rejected...)
+			replayErr = errors.Join(replayErr, err)
+		} else {
+			slog.Warn("Click events quarantined after repeated replay failures",
+				"clicks", len(rejected), "max_attempts", maxAttempts, "path", j.path+".rejected")
+		}
+	}
+	if err := j.rewrite(kept); err != nil {
+		return replayed, errors.Join(replayErr, err)
+	}
+	if replayErr != nil {
+		return replayed, fmt.Errorf("%d click event(s) could not be replayed: %w", len(kept)+len(rejected), replayErr)
+	}
+	return replayed, nil
+}
+
+// Drain refuse les nouveaux clics d'Enqueue et attend l'écriture de ceux qui sont en file.
+// Append reste utilisable : Drain peut être appelée alors que des workers écrivent encore dans le journal.
+func (j *ClickJournal) Drain() {
+	j.closeMu.Lock()
+	if !j.closed {
+		j.closed = true
+		close(j.overflow)
+	}
+	j.closeMu.Unlock()
+	<-j.overflowDone
+}
+
+// Close écrit les clics encore en file puis ferme le fichier du journal.
+func (j *ClickJournal) Close() error {
+	j.Drain()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// readAll lit tous les événements du journal. Une ligne illisible (ex: écriture interrompue) est ignorée.
+func (j *ClickJournal) readAll() ([]journalEntry, error) {
+	if _, err := j.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("failed to read click journal: %w", err)
+	}
+
+	var entries []journalEntry
+	scanner := bufio.NewScanner(j.file)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read click journal: %w", err)
+	}
+	return entries, nil
+}
+
+// rewrite remplace le contenu du journal par 'entries'.
+func (j *ClickJournal) rewrite(entries []journalEntry) error {
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate click journal: %w", err)
+	}
+	return writeEntries(j.file, entries)
+}
+
+// appendEntries ajoute 'entries' à la fin du fichier 'path', créé au besoin.
+func appendEntries(path string, entries []journalEntry) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open click quarantine %s: %w", path, err)
+	}
+	if err := writeEntries(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeEntries écrit 'entries' (une ligne JSON chacune) dans 'file' et force leur écriture sur disque.
+func writeEntries(file *os.File, entries []journalEntry) error {
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode click event: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write click journal: %w", err)
+	}
+	return file.Sync()
+}
+
+// clickEvents retourne les événements des lignes 'entries'.
+func clickEvents(entries []journalEntry) []models.ClickEvent {
+	events := make([]models.ClickEvent, 0, len(entries))
+	for _, entry := range entries {
+		events = append(events, entry.ClickEvent)
+	}
+	return events
+}
@@ -0,0 +1,248 @@
+package workers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+var errDatabaseDown = errors.New("database down")
+
+// openTestJournal ouvre un journal vide dans un répertoire temporaire.
+func openTestJournal(t *testing.T) *ClickJournal {
+	t.Helper()
+	j, err := OpenClickJournal(filepath.Join(t.TempDir(), "clicks.journal"), 16)
+	if err != nil {
+		t.Fatalf("OpenClickJournal: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+// events retourne un clic par identifiant de lien.
+func events(linkIDs ...uint) []models.ClickEvent {
+	events := make([]models.ClickEvent, 0, len(linkIDs))
+	for _, id := range linkIDs {
+		events = append(events, models.ClickEvent{LinkID: id, Timestamp: time.Unix(int64(id), 0).UTC()})
+	}
+	return events
+}
+
+// readEntries lit les lignes du fichier 'path' (absent = aucune ligne).
+func readEntries(t *testing.T, path string) []journalEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var entries []journalEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid line in %s: %q", path, scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func linkIDs(entries []journalEntry) []uint {
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.LinkID)
+	}
+	return ids
+}
+
+func TestClickJournalReplay(t *testing.T) {
+	tests := []struct {
+		name         string
+		journal      []uint
+		persist      func(batch []models.ClickEvent) error
+		wantReplayed int
+		wantErr      bool
+		wantKept     []uint
+		wantAttempts []int // Nombre de rejeux en échec de chaque événement conservé
+		wantCalls    int
+	}{
+		{
+			name:         "all events written",
+			journal:      []uint{1, 2, 3, 4, 5},
+			persist:      func([]models.ClickEvent) error { return nil },
+			wantReplayed: 5,
+			wantCalls:    3,
+		},
+		{
+			name:         "empty journal",
+			persist:      func([]models.ClickEvent) error { return nil },
+			wantReplayed: 0,
+		},
+		{
+			name:     "database down stops at the first batch",
+			journal:  []uint{1, 2, 3, 4, 5},
+			persist:  func([]models.ClickEvent) error { return errDatabaseDown },
+			wantErr:  true,
+			wantKept: []uint{1, 2, 3, 4, 5},
+			// Seuls les événements du premier lot ont été essayés.
+			wantAttempts: []int{1, 1, 0, 0, 0},
+			// Le premier lot, puis chacun de ses deux événements.
+			wantCalls: 3,
+		},
+		{
+			name:    "failing event does not block the others",
+			journal: []uint{1, 2, 3, 4, 5},
+			persist: func(batch []models.ClickEvent) error {
+				for _, event := range batch {
+					if event.LinkID == 3 {
+						return errDatabaseDown
+					}
+				}
+				return nil
+			},
+			wantReplayed: 4,
+			wantErr:      true,
+			wantKept:     []uint{3},
+			wantAttempts: []int{1},
+			wantCalls:    5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := openTestJournal(t)
+			if err := j.Append(events(tt.journal...)...); err != nil {
+				t.Fatalf("Append: %v", err)
+			}
+
+			calls := 0
+			replayed, err := j.Replay(2, 3, func(batch []models.ClickEvent) error {
+				calls++
+				return tt.persist(batch)
+			})
+			if replayed != tt.wantReplayed || (err != nil) != tt.wantErr {
+				t.Errorf("Replay = %d, %v; want %d, error %v", replayed, err, tt.wantReplayed, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("persist called %d times, want %d", calls, tt.wantCalls)
+			}
+
+			kept := readEntries(t, j.path)
+			if got := linkIDs(kept); !slices.Equal(got, tt.wantKept) {
+				t.Errorf("journal keeps links %v, want %v", got, tt.wantKept)
+			}
+			for i, entry := range kept {
+				if i < len(tt.wantAttempts) && entry.Attempts != tt.wantAttempts[i] {
+					t.Errorf("kept event of link %d has %d attempt(s), want %d", entry.LinkID, entry.Attempts, tt.wantAttempts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClickJournalQuarantine(t *testing.T) {
+	j := openTestJournal(t)
+	if err := j.Append(events(1, 2, 3)...); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	persist := func(batch []models.ClickEvent) error {
+		for _, event := range batch {
+			if event.LinkID == 2 {
+				return errDatabaseDown
+			}
+		}
+		return nil
+	}
+
+	const maxAttempts = 3
+	var persisted []uint
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		replayed, err := j.Replay(10, maxAttempts, func(batch []models.ClickEvent) error {
+			if err := persist(batch); err != nil {
+				return err
+			}
+			for _, event := range batch {
+				persisted = append(persisted, event.LinkID)
+			}
+			return nil
+		})
+		if err == nil {
+			t.Fatalf("replay %d succeeded, want an error for the failing event", attempt)
+		}
+		if attempt == 1 && replayed != 2 {
+			t.Errorf("first replay wrote %d event(s), want 2", replayed)
+		}
+		kept := readEntries(t, j.path)
+		if attempt < maxAttempts && (len(kept) != 1 || kept[0].Attempts != attempt) {
+			t.Fatalf("after replay %d, journal = %+v; want link 2 with %d attempt(s)", attempt, kept, attempt)
+		}
+	}
+
+	if !slices.Equal(persisted, []uint{1, 3}) {
+		t.Errorf("persisted links %v, want [1 3]", persisted)
+	}
+	if kept := readEntries(t, j.path); len(kept) != 0 {
+		t.Errorf("journal still holds %v after %d attempts", linkIDs(kept), maxAttempts)
+	}
+	rejected := readEntries(t, j.path+".rejected")
+	if len(rejected) != 1 || rejected[0].LinkID != 2 || rejected[0].Attempts != maxAttempts {
+		t.Errorf("quarantine = %+v, want link 2 with %d attempts", rejected, maxAttempts)
+	}
+
+	// La quarantaine n'est plus rejouée.
+	if replayed, err := j.Replay(10, maxAttempts, persist); replayed != 0 || err != nil {
+		t.Errorf("replay after quarantine = %d, %v; want 0, nil", replayed, err)
+	}
+}
+
+func TestClickJournalSkipsUnreadableLines(t *testing.T) {
+	j := openTestJournal(t)
+	if err := j.Append(events(1)...); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	// Écriture interrompue au milieu d'une ligne.
+	if _, err := j.file.WriteString(`{"LinkID":2,"Timest` + "\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := j.Append(events(3)...); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	var persisted []uint
+	replayed, err := j.Replay(10, 3, func(batch []models.ClickEvent) error {
+		for _, event := range batch {
+			persisted = append(persisted, event.LinkID)
+		}
+		return nil
+	})
+	if replayed != 2 || err != nil || !slices.Equal(persisted, []uint{1, 3}) {
+		t.Errorf("Replay = %d, %v, persisted %v; want 2, nil, [1 3]", replayed, err, persisted)
+	}
+}
+
+func TestClickJournalEnqueue(t *testing.T) {
+	j := openTestJournal(t)
+	for _, event := range events(1, 2, 3) {
+		if !j.Enqueue(event) {
+			t.Fatalf("Enqueue(%d) refused", event.LinkID)
+		}
+	}
+	j.Drain()
+	if j.Enqueue(events(4)[0]) {
+		t.Error("Enqueue accepted an event after Drain")
+	}
+	if got := linkIDs(readEntries(t, j.path)); !slices.Equal(got, []uint{1, 2, 3}) {
+		t.Errorf("journal holds links %v, want [1 2 3]", got)
+	}
+}
@@ -1,47 +1,197 @@
 package workers
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
 
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Nécessaire pour interagir avec le ClickRepository
+	"github.com/axellelanca/urlshortener/internal/textutil"
+	"github.com/axellelanca/urlshortener/internal/tracing"
+	"github.com/axellelanca/urlshortener/internal/useragent"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// BatchConfig contrôle le regroupement des clics avant écriture en base.
+type BatchConfig struct {
+	// Size est le nombre maximal de clics écrits en une seule requête.
+	Size int
+	// FlushInterval est la latence maximale avant qu'un lot incomplet ne soit écrit.
+	FlushInterval time.Duration
+	// MaxRetries est le nombre de nouvelles tentatives après l'échec d'une écriture.
+	MaxRetries int
+	// RetryBackoff est le délai avant la première nouvelle tentative ; il double à chaque essai.
+	RetryBackoff time.Duration
+}
+
 // StartClickWorkers lance un pool de goroutines "workers" pour traiter les événements de clic.
-// Chaque worker lira depuis le même 'clickEventsChan' et utilisera le 'clickRepo' pour la persistance.
-func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
-	log.Printf("Starting %d click worker(s)...", workerCount)
+// Chaque worker lira depuis le même 'clickEventsChan', regroupera les clics par lots et utilisera
+// le 'clickRepo' pour la persistance. Les lots qui ne peuvent pas être écrits sont déversés dans 'journal'.
+// Pour arrêter les workers, fermer 'clickEventsChan' puis attendre le WaitGroup retourné :
+// chaque worker écrit alors les événements restants avant de se terminer.
+func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, batch BatchConfig, journal *ClickJournal) *sync.WaitGroup {
+	slog.Info("Starting click workers", "workers", workerCount, "batch_size", batch.Size, "flush_interval", batch.FlushInterval)
+	var wg sync.WaitGroup
 	for i := 0; i < workerCount; i++ {
 		// Lance chaque worker dans sa propre goroutine.
 		// Le channel est passé en lecture seule (<-chan) pour renforcer l'immutabilité du channel à l'intérieur du worker.
-		go clickWorker(clickEventsChan, clickRepo)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clickWorker(clickEventsChan, clickRepo, batch, journal)
+		}()
 	}
+	return &wg
+}
+
+// ReplayJournal persiste les événements restés dans le journal lors d'une exécution précédente.
+// Elle doit être appelée au démarrage, avant que le serveur n'accepte des requêtes.
+func ReplayJournal(journal *ClickJournal, clickRepo repository.ClickRepository, batchSize, maxAttempts int) (int, error) {
+	return journal.Replay(batchSize, maxAttempts, func(events []models.ClickEvent) error {
+		return clickRepo.CreateClicks(context.Background(), toClicks(events))
+	})
 }
 
 // clickWorker est la fonction exécutée par chaque goroutine worker.
-// Elle tourne indéfiniment, lisant les événements de clic dès qu'ils sont disponibles dans le channel.
-func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
-	for event := range clickEventsChan { // Boucle qui lit les événements du channel
-		// Convertir le 'ClickEvent' (reçu du channel) en un modèle 'models.Click'.
-		click := &models.Click{
-			LinkID:    event.LinkID,
-			Timestamp: event.Timestamp,
-			UserAgent: event.UserAgent,
-			IPAddress: event.IP, // Utilise le champ IP du ClickEvent
+// Elle accumule les événements reçus et écrit le lot dès qu'il est plein ou que FlushInterval est écoulé.
+// Elle se termine lorsque le channel est fermé, après avoir écrit le dernier lot.
+func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, batch BatchConfig, journal *ClickJournal) {
+	pending := make([]models.ClickEvent, 0, batch.Size)
+	ticker := time.NewTicker(batch.FlushInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		persistBatch(pending, clickRepo, batch, journal)
+		pending = make([]models.ClickEvent, 0, batch.Size)
+	}
+
+	for {
+		select {
+		case event, ok := <-clickEventsChan:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, event)
+			if len(pending) >= batch.Size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// persistBatch écrit un lot de clics en base, avec des nouvelles tentatives espacées de manière exponentielle.
+// Si toutes les tentatives échouent, le lot est déversé dans le journal pour être rejoué au prochain démarrage.
+// Le span de l'écriture est relié aux spans des redirections à l'origine des clics.
+func persistBatch(events []models.ClickEvent, clickRepo repository.ClickRepository, batch BatchConfig, journal *ClickJournal) {
+	ctx, span := tracing.Start(context.Background(), "clicks.persist_batch",
+		trace.WithLinks(traceLinks(events)...),
+		trace.WithAttributes(attribute.Int("clicks.count", len(events))))
+	defer span.End()
+
+	clicks := toClicks(events)
+	backoff := batch.RetryBackoff
+
+	var err error
+	for attempt := 0; attempt <= batch.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		start := time.Now()
+		err = clickRepo.CreateClicks(ctx, clicks)
+		metrics.ClickInsertDuration.Observe(time.Since(start).Seconds())
+		if err == nil {
+			slog.Debug("Click batch recorded", "clicks", len(clicks))
+			return
+		}
+		metrics.ClickInsertErrors.Inc()
+		slog.Error("Failed to save click batch",
+			"clicks", len(clicks), "attempt", attempt+1, "max_attempts", batch.MaxRetries+1,
+			"request_ids", requestIDs(events), "error", err)
+	}
+
+	tracing.RecordError(span, err)
+	if journalErr := journal.Append(events...); journalErr != nil {
+		// Dernier recours : les clics sont perdus, on les trace au moins dans les logs.
+		slog.Error("Failed to spill click batch to journal, events lost",
+			"clicks", len(events), "request_ids", requestIDs(events), "error", journalErr)
+		return
+	}
+	slog.Warn("Click batch spilled to journal after repeated write failures",
+		"clicks", len(events), "request_ids", requestIDs(events))
+}
+
+// requestIDs retourne les identifiants des requêtes à l'origine des événements, pour relier
+// un échec d'écriture aux requêtes de redirection concernées.
+func requestIDs(events []models.ClickEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.RequestID != "" {
+			ids = append(ids, event.RequestID)
 		}
+	}
+	return ids
+}
 
-		// Persiste le clic en base de données via le 'clickRepo'
-		err := clickRepo.CreateClick(click)
-		if err != nil {
-			// Si une erreur se produit lors de l'enregistrement, logguez-la.
-			// L'événement est "perdu" pour ce TP, mais dans un vrai système,
-			// vous pourriez le remettre dans une file de retry ou une file d'erreurs.
-			log.Printf("ERROR: Failed to save click for LinkID %d (UserAgent: %s, IP: %s): %v",
-				event.LinkID, event.UserAgent, event.IP, err)
-
-		} else {
-			// Log optionnel pour confirmer l'enregistrement (utile pour le débogage)
-			log.Printf("Click recorded successfully for LinkID %d", event.LinkID)
+// traceLinks retourne les contextes de trace transportés par les événements.
+func traceLinks(events []models.ClickEvent) []trace.Link {
+	links := make([]trace.Link, 0, len(events))
+	for _, event := range events {
+		if sc := tracing.Extract(event.TraceContext); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
 		}
 	}
+	return links
+}
+
+// toClicks convertit les 'ClickEvent' (reçus du channel) en modèles 'models.Click'.
+// L'analyse du User-Agent est faite ici plutôt que dans le handler pour ne pas ralentir la redirection.
+// Tailles des colonnes de la table clicks : les en-têtes plus longs sont tronqués plutôt que de faire
+// échouer l'écriture du lot sur PostgreSQL et MySQL (SQLite ne vérifie pas la taille).
+const (
+	maxUserAgentLength = 255
+	maxReferrerLength  = 2048
+	maxHostLength      = 255
+)
+
+func toClicks(events []models.ClickEvent) []*models.Click {
+	clicks := make([]*models.Click, 0, len(events))
+	for _, event := range events {
+		ua := useragent.Parse(event.UserAgent)
+		clicks = append(clicks, &models.Click{
+			LinkID:       event.LinkID,
+			Timestamp:    event.Timestamp,
+			UserAgent:    textutil.Truncate(event.UserAgent, maxUserAgentLength),
+			IPAddress:    event.IP, // Utilise le champ IP du ClickEvent
+			Referrer:     textutil.Truncate(event.Referrer, maxReferrerLength),
+			ReferrerHost: textutil.Truncate(referrerHost(event.Referrer), maxHostLength),
+			Browser:      ua.Browser,
+			OS:           ua.OS,
+			Device:       ua.Device,
+		})
+	}
+	return clicks
+}
+
+// referrerHost extrait l'hôte de l'en-tête Referer (vide si absent ou invalide).
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
 }
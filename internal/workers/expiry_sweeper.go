@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// ExpirySweeper parcourt périodiquement les liens pour marquer ceux qui ont expiré
+// (date dépassée ou quota de clics atteint). Un lien marqué n'est plus surveillé par le moniteur.
+type ExpirySweeper struct {
+	linkService *services.LinkService
+	interval    time.Duration
+	logger      *slog.Logger
+}
+
+// NewExpirySweeper crée et retourne une nouvelle instance d'ExpirySweeper.
+func NewExpirySweeper(linkService *services.LinkService, interval time.Duration) *ExpirySweeper {
+	return &ExpirySweeper{
+		linkService: linkService,
+		interval:    interval,
+		logger:      slog.Default().With("component", "sweeper"),
+	}
+}
+
+// Start lance la boucle de balayage périodique jusqu'à l'annulation de 'ctx'.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (s *ExpirySweeper) Start(ctx context.Context) {
+	s.logger.Info("Démarrage du sweeper d'expiration", "interval", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Arrêt du sweeper d'expiration.")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep marque les liens expirés et loggue le nombre de liens concernés.
+func (s *ExpirySweeper) sweep(ctx context.Context) {
+	count, err := s.linkService.ExpireLinks(ctx)
+	if err != nil {
+		s.logger.Error("Erreur lors du marquage des liens expirés", "error", err)
+		return
+	}
+	if count > 0 {
+		s.logger.Info("Liens marqués comme expirés", "count", count)
+	}
+}